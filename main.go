@@ -34,6 +34,9 @@ func main() {
 	case "demo":
 		demoCmd := commands.NewDemoCommand()
 		err = demoCmd.Execute(os.Args[2:])
+	case "identities":
+		identitiesCmd := commands.NewIdentitiesCommand()
+		err = identitiesCmd.Execute(os.Args[2:])
 	case "test":
 		err = runTests()
 	default:
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"git-rewrite/pkg/cli/config"
+	"git-rewrite/pkg/git"
+	"git-rewrite/pkg/utils"
+)
+
+// identitiesDateFormat は日時の出力形式
+const identitiesDateFormat = "2006-01-02"
+
+// IdentitiesCommand はidentitiesコマンドを実行する
+type IdentitiesCommand struct{}
+
+// NewIdentitiesCommand は新しいIdentitiesCommandを作成する
+func NewIdentitiesCommand() *IdentitiesCommand {
+	return &IdentitiesCommand{}
+}
+
+// Execute はidentitiesコマンドを実行する
+func (c *IdentitiesCommand) Execute(args []string) error {
+	config, err := config.ParseIdentitiesArgs(args)
+	if err != nil {
+		fmt.Printf("引数解析エラー: %v\n", err)
+		fmt.Println("")
+		fmt.Println("使用方法: git-rewrite identities --target-dir <directory> [--format table|csv|json]")
+		return err
+	}
+
+	// 対象ディレクトリの絶対パスを取得
+	absTargetDir, err := filepath.Abs(config.TargetDir)
+	if err != nil {
+		return fmt.Errorf("ディレクトリパス解決に失敗しました: %v", err)
+	}
+
+	// Gitリポジトリを検索
+	gitDirs, err := utils.FindGitDirs(absTargetDir)
+	if err != nil {
+		return fmt.Errorf("Gitリポジトリの検索に失敗しました: %v", err)
+	}
+
+	var stats []git.IdentityStat
+	for _, gitDir := range gitDirs {
+		repoStats, err := git.CollectIdentities(gitDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %s のidentity集計に失敗しました: %v\n", gitDir, err)
+			continue
+		}
+		stats = append(stats, repoStats...)
+	}
+
+	// 出力先を決定
+	var out io.Writer = os.Stdout
+	if config.Output != "" {
+		file, err := os.Create(config.Output)
+		if err != nil {
+			return fmt.Errorf("出力ファイル作成エラー: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch config.Format {
+	case "csv":
+		err = c.writeCSV(out, stats)
+	case "json":
+		err = c.writeJSON(out, stats)
+	default:
+		err = c.writeTable(out, stats, len(gitDirs))
+	}
+	if err != nil {
+		return fmt.Errorf("出力エラー: %v", err)
+	}
+
+	if config.Output != "" {
+		fmt.Printf("✅ %d 件のidentityを %s に出力しました。\n", len(stats), config.Output)
+	}
+	return nil
+}
+
+// writeTable は表形式で出力する
+func (c *IdentitiesCommand) writeTable(out io.Writer, stats []git.IdentityStat, repoCount int) error {
+	fmt.Fprintf(out, "対象リポジトリ: %d個\n\n", repoCount)
+	if len(stats) == 0 {
+		fmt.Fprintln(out, "identityが見つかりませんでした。")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "REPOSITORY\tROLE\tNAME\tEMAIL\tCOUNT\tFIRST\tLAST")
+	for _, stat := range stats {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			stat.Repository, stat.Role, stat.Name, stat.Email, stat.Count,
+			stat.First.Format(identitiesDateFormat), stat.Last.Format(identitiesDateFormat))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	// 全リポジトリを通した重複のないidentity数
+	unique := make(map[string]bool)
+	for _, stat := range stats {
+		unique[stat.Name+" <"+stat.Email+">"] = true
+	}
+	fmt.Fprintf(out, "\n重複のないidentity: %d件\n", len(unique))
+	return nil
+}
+
+// writeCSV はCSV形式で出力する
+func (c *IdentitiesCommand) writeCSV(out io.Writer, stats []git.IdentityStat) error {
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"repository", "role", "name", "email", "count", "first", "last"})
+	for _, stat := range stats {
+		writer.Write([]string{
+			stat.Repository, stat.Role, stat.Name, stat.Email, strconv.Itoa(stat.Count),
+			stat.First.Format(identitiesDateFormat), stat.Last.Format(identitiesDateFormat),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeJSON はJSON形式で出力する
+func (c *IdentitiesCommand) writeJSON(out io.Writer, stats []git.IdentityStat) error {
+	if stats == nil {
+		stats = []git.IdentityStat{}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(stats)
+}
@@ -1,12 +1,18 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"git-rewrite/pkg/cli/config"
+	"git-rewrite/pkg/git"
+	"git-rewrite/pkg/github"
+	"git-rewrite/pkg/gitlab"
 	"git-rewrite/pkg/rewriter"
+	"git-rewrite/pkg/rules"
 	"git-rewrite/pkg/utils"
 )
 
@@ -35,6 +41,13 @@ func (c *RewriteCommand) Execute(args []string) error {
 		fmt.Println("🐛 デバッグモードが有効です")
 	}
 
+	// noreplyメールアドレスの取得
+	if config.NoreplyEmail {
+		if err := c.resolveNoreplyEmail(config); err != nil {
+			return err
+		}
+	}
+
 	// 設定の表示
 	c.displayConfig(config)
 
@@ -61,19 +74,102 @@ func (c *RewriteCommand) Execute(args []string) error {
 	}
 	fmt.Println()
 
+	// ディレクトリごとのidentityルールを読み込み
+	var identityRules *rules.IdentityRules
+	if config.IdentityRules != "" {
+		identityRules, err = rules.LoadIdentityRules(config.IdentityRules)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("identityルールファイル: %s (%d 件のルール)\n", config.IdentityRules, len(identityRules.Rules))
+	}
+
+	// 履歴を書き換える前に、書き換え先のメールアドレスがGitHubアカウントに登録されているか確認
+	if err := c.verifyEmail(config, identityRules); err != nil {
+		return err
+	}
+
+	// 書き換え先の所有者の振り分けルールを読み込み
+	var ownerRules *rules.OwnerRules
+	if config.OwnerRules != "" {
+		ownerRules, err = rules.LoadOwnerRules(config.OwnerRules)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("所有者ルールファイル: %s (%d 件のルール)\n", config.OwnerRules, len(ownerRules.Rules))
+	}
+
 	// Rewriterを作成
 	gitRewriter := c.createRewriter(config)
 
+	// 書き換え先のリポジトリ名のルール
+	nameRules, err := rules.NewRepoNameRules(config.NameTemplate, config.NameMap)
+	if err != nil {
+		return err
+	}
+	if nameRules != nil {
+		gitRewriter.SetRepoNamer(nameRules)
+		if config.NameMap != "" {
+			fmt.Printf("リポジトリ名対応表ファイル: %s (%d 件)\n", config.NameMap, len(nameRules.Names))
+		}
+	}
+
+	// ブランチ・タグの名前変更ルール
+	if config.RefRules != "" {
+		refRules, err := rules.LoadRefRules(config.RefRules)
+		if err != nil {
+			return err
+		}
+		gitRewriter.SetRefRenamer(refRules)
+		fmt.Printf("参照名ルールファイル: %s (ブランチ %d 件, タグ %d 件のルール)\n", config.RefRules, len(refRules.Branches), len(refRules.Tags))
+	}
+
+	// contributorの匿名化
+	if config.Anonymize {
+		if err := git.CheckMapOutsideRepos(config.AnonymizeMap, gitDirs); err != nil {
+			return err
+		}
+		anonymizer, err := git.NewAnonymizer(config.AnonymizeSalt, config.AnonymizeMap)
+		if err != nil {
+			return err
+		}
+		gitRewriter.SetAnonymizer(anonymizer)
+	}
+
+	// リポジトリごとのRewriterと全体の進捗表示を決定する
+	batch, err := c.planBatch(config, absTargetDir, gitDirs, gitRewriter, identityRules, ownerRules)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("書き換え対象のコミット数: 合計 %d\n", batch.totalCommits)
+
 	// 結果を追跡
 	var successCount int
 	var failedRepos []string
 	var pushFailedRepos []string
+	var results []*rewriter.RewriteResult
 
 	// 各リポジトリを処理
 	for i, gitDir := range gitDirs {
 		fmt.Printf("\n=== [%d/%d] %s でスクリプトを実行します ===\n", i+1, len(gitDirs), gitDir)
 
-		result := gitRewriter.ProcessRepository(gitDir)
+		if batch.skipped[gitDir] {
+			failedRepos = append(failedRepos, gitDir)
+			batch.progress.CompleteRepository(batch.commitCounts[gitDir])
+			fmt.Printf("✗ %s は書き換え先が他のリポジトリと同じため、処理をスキップしました。\n", gitDir)
+			continue
+		}
+
+		repoRewriter := batch.rewriters[gitDir]
+		if rule := identityRules.Match(absTargetDir, gitDir); rule != nil {
+			fmt.Printf("identityルール '%s' を適用します: %s <%s> → %s\n", rule.Path, repoRewriter.GitHubUser, repoRewriter.GitHubEmail,
+				utils.GetTargetOwner(repoRewriter.GitHubUser, repoRewriter.Owner, repoRewriter.Organization))
+		}
+
+		result := repoRewriter.ProcessRepository(gitDir)
+		batch.progress.CompleteRepository(batch.commitCounts[gitDir])
+		c.displayPreflight(result)
+		results = append(results, result)
 
 		if result.Success {
 			successCount++
@@ -90,15 +186,243 @@ func (c *RewriteCommand) Execute(args []string) error {
 	}
 
 	// 最終結果の表示
+	c.displaySizeReport(results)
 	return c.displayResults(successCount, len(gitDirs), failedRepos, pushFailedRepos)
 }
 
+// repositoryBatch は一括処理するリポジトリごとのRewriterと全体の進捗表示を表す
+type repositoryBatch struct {
+	rewriters    map[string]*rewriter.Rewriter // リポジトリ → 適用するRewriter
+	skipped      map[string]bool               // 書き換え先の衝突により処理しないリポジトリ
+	commitCounts map[string]int                // リポジトリ → コミット数
+	totalCommits int
+	progress     *git.BatchProgress
+}
+
+// planBatch は全体の進捗表示を設定したうえで、リポジトリごとのRewriterを決定する
+// ルールに一致したリポジトリのRewriterはコピーのため、進捗表示はコピーを作成する前に設定する
+func (c *RewriteCommand) planBatch(config *config.Config, absTargetDir string, gitDirs []string, gitRewriter *rewriter.Rewriter,
+	identityRules *rules.IdentityRules, ownerRules *rules.OwnerRules) (*repositoryBatch, error) {
+	batch := &repositoryBatch{
+		rewriters:    make(map[string]*rewriter.Rewriter),
+		commitCounts: make(map[string]int),
+	}
+
+	// 全体の進捗表示のため、各リポジトリのコミット数を事前に数える
+	for _, gitDir := range gitDirs {
+		if count, err := git.CountCommits(gitDir); err == nil {
+			batch.commitCounts[gitDir] = count
+			batch.totalCommits += count
+		}
+	}
+	batch.progress = git.NewBatchProgress(len(gitDirs), batch.totalCommits)
+	gitRewriter.SetBatchProgress(batch.progress)
+
+	for _, gitDir := range gitDirs {
+		batch.rewriters[gitDir] = gitRewriter
+		if rule := identityRules.Match(absTargetDir, gitDir); rule != nil {
+			batch.rewriters[gitDir] = gitRewriter.WithIdentity(rule.User, rule.Email, rule.Owner, rule.Organization)
+		}
+		// 所有者の振り分けルールはidentityルールの所有者より優先する
+		sourceOwner, sourceRepo := c.sourceRepository(config, gitDir)
+		if rule := ownerRules.Match(absTargetDir, gitDir, sourceOwner, sourceRepo); rule != nil {
+			batch.rewriters[gitDir] = batch.rewriters[gitDir].WithIdentity("", "", rule.Owner, rule.Organization)
+			fmt.Printf("%s → %s に振り分けます\n", gitDir, rule.Target())
+		}
+	}
+
+	// 何も書き換える前に、書き換え先が同じリポジトリがないか確認する
+	skipped, err := c.resolveDestinations(config, gitDirs, batch.rewriters)
+	if err != nil {
+		return nil, err
+	}
+	batch.skipped = skipped
+	return batch, nil
+}
+
+// sourceRepository は元のリモートのオーナーとリポジトリ名を返す
+// リモートがない・書き換え対象のホストのURLではない場合は、オーナーを空、リポジトリ名をディレクトリ名とする
+func (c *RewriteCommand) sourceRepository(config *config.Config, gitDir string) (string, string) {
+	if stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", config.Remote); err == nil {
+		if remote, err := c.remoteHosts(config).ParseSource(strings.TrimSpace(stdout)); err == nil {
+			return remote.Owner, remote.Repo
+		}
+	}
+	return "", filepath.Base(gitDir)
+}
+
+// destinationHost は書き換え先のホストを返す（GitLabではサブグループをオーナーとして扱う）
+func (c *RewriteCommand) destinationHost(config *config.Config) utils.Host {
+	return utils.NewHost(config.GitHubHost, config.Provider == "gitlab")
+}
+
+// remoteHosts は書き換え対象のリモートのホストと書き換え先のホストを返す
+// github.com以外の書き換え元のホストでは、GitLabのサブグループを含むURLも書き換え対象とする
+func (c *RewriteCommand) remoteHosts(config *config.Config) git.RemoteHosts {
+	hosts := git.RemoteHosts{Destination: c.destinationHost(config)}
+	for _, name := range config.SourceHosts {
+		host := utils.NewHost(name, false)
+		host.Nested = !host.IsGitHubDotCom()
+		hosts.Sources = append(hosts.Sources, host)
+	}
+	return hosts
+}
+
+// githubClient は書き換え先のホストのGitHub APIクライアントを返す
+func (c *RewriteCommand) githubClient(config *config.Config) *github.Client {
+	return github.NewClientForHost(config.GitHubToken, c.destinationHost(config), config.GitHubAPIURL)
+}
+
+// resolveDestinations は全リポジトリの書き換え先を求め、衝突を設定された方針で解決する
+// 処理しないリポジトリを返す。リポジトリ名を変更するリポジトリはrepoRewritersを差し替える
+func (c *RewriteCommand) resolveDestinations(config *config.Config, gitDirs []string, repoRewriters map[string]*rewriter.Rewriter) (map[string]bool, error) {
+	var destinations []*rewriter.Destination
+	for _, gitDir := range gitDirs {
+		destination, err := repoRewriters[gitDir].PlanDestination(gitDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s の書き換え先を決定できません: %v", gitDir, err)
+		}
+		if destination != nil {
+			destinations = append(destinations, destination)
+		}
+	}
+
+	collisions := rewriter.FindCollisions(destinations)
+	if len(collisions) == 0 {
+		return nil, nil
+	}
+	fmt.Println("⚠️  書き換え先が同じリポジトリがあります:")
+	for _, collision := range collisions {
+		fmt.Printf("  %s:\n", collision.Destination)
+		for _, destination := range collision.Destinations {
+			fmt.Printf("    - %s\n", destination.GitDir)
+		}
+	}
+
+	skipped, renamed, err := rewriter.ResolveCollisions(destinations, config.OnCollision)
+	if err != nil {
+		fmt.Println("   --on-collision skip または --on-collision suffix を指定するか、--name-map でリポジトリ名を指定してください。")
+		return nil, err
+	}
+	for _, gitDir := range gitDirs {
+		if repoName, ok := renamed[gitDir]; ok {
+			repoRewriters[gitDir] = repoRewriters[gitDir].WithRepoName(repoName)
+			fmt.Printf("  %s はリポジトリ名を %s に変更します\n", gitDir, repoName)
+		}
+	}
+	fmt.Println()
+	return skipped, nil
+}
+
+// resolveNoreplyEmail はトークンのユーザーのnoreplyメールアドレスを書き換え先メールアドレスに設定する
+func (c *RewriteCommand) resolveNoreplyEmail(config *config.Config) error {
+	user, err := c.githubClient(config).GetCurrentUser()
+	if err != nil {
+		return fmt.Errorf("noreplyメールアドレスの取得に失敗しました: %v", err)
+	}
+	if user.Login != config.GitHubUser {
+		fmt.Printf("⚠️  トークンのユーザー（%s）と --user（%s）が異なります。%s のnoreplyメールアドレスを使用します。\n",
+			user.Login, config.GitHubUser, user.Login)
+	}
+	config.GitHubEmail = user.NoreplyEmail()
+	fmt.Printf("✅ noreplyメールアドレスを使用します: %s\n", config.GitHubEmail)
+	return nil
+}
+
+// verifyEmail は書き換え先のメールアドレス（identityルールのものを含む）がGitHubアカウントに登録・確認済みかを検証する
+func (c *RewriteCommand) verifyEmail(config *config.Config, identityRules *rules.IdentityRules) error {
+	if config.EmailCheck == "off" {
+		return nil
+	}
+	if config.Provider != "github" {
+		fmt.Printf("ℹ️  %s ではメールアドレスの登録確認を行いません。\n\n", config.Provider)
+		return nil
+	}
+
+	emails := c.destinationEmails(config, identityRules)
+	if len(emails) == 0 {
+		return nil
+	}
+
+	fmt.Println("メールアドレスがGitHubアカウントに登録されているか確認しています...")
+	client := c.githubClient(config)
+	var failed []string
+	for _, email := range emails {
+		err := client.VerifyAccountEmail(email)
+		if err == nil {
+			fmt.Printf("✅ %s はGitHubアカウントに登録・確認済みです。\n", email)
+			continue
+		}
+		if errors.Is(err, github.ErrMissingEmailScope) {
+			// スコープがない場合は確認できないだけのため、中止せずに続行する
+			fmt.Printf("⚠️  メールアドレスの登録確認を行わずに続行します: %v\n", err)
+			fmt.Println("   確認するにはトークンに 'user:email' スコープを付与してください（確認しない場合は --email-check off を指定）。")
+			fmt.Println()
+			return nil
+		}
+		fmt.Printf("⚠️  メールアドレスの確認に失敗しました: %v\n", err)
+		failed = append(failed, email)
+	}
+	fmt.Println()
+
+	if len(failed) == 0 {
+		return nil
+	}
+	if config.EmailCheck == "warn" {
+		fmt.Println("   書き換えたコミットがGitHubアカウントに関連付けられない可能性があります。")
+		fmt.Println()
+		return nil
+	}
+	fmt.Println("   確認せずに続行する場合は --email-check warn または --email-check off を指定してください。")
+	return fmt.Errorf("メールアドレスの確認に失敗しました: %s", strings.Join(failed, ", "))
+}
+
+// destinationEmails は登録確認が必要な書き換え先のメールアドレスを重複なく返す
+// noreplyメールアドレスはアカウント情報から取得しているため確認不要
+func (c *RewriteCommand) destinationEmails(config *config.Config, identityRules *rules.IdentityRules) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	add := func(email string) {
+		key := strings.ToLower(strings.TrimSpace(email))
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		emails = append(emails, email)
+	}
+
+	if !config.NoreplyEmail {
+		add(config.GitHubEmail)
+	}
+	if identityRules != nil {
+		for _, rule := range identityRules.Rules {
+			add(rule.Email)
+		}
+	}
+	return emails
+}
+
 // displayConfig は設定情報を表示する
 func (c *RewriteCommand) displayConfig(config *config.Config) {
 	fmt.Printf("📋 設定情報:\n")
 	fmt.Printf("  GitHubユーザー: %s\n", config.GitHubUser)
 	fmt.Printf("  GitHubメール: %s\n", config.GitHubEmail)
 	fmt.Printf("  対象ディレクトリ: %s\n", config.TargetDir)
+	if config.Provider == "gitlab" {
+		client := gitlab.NewClient(config.GitHubToken, c.destinationHost(config), config.GitHubAPIURL)
+		fmt.Printf("  書き換え先: GitLab（ホスト: %s、API: %s）\n", client.Host.Name, client.BaseURL)
+	} else if config.GitHubHost != "" {
+		client := c.githubClient(config)
+		fmt.Printf("  GitHubホスト: %s（API: %s）\n", client.Host.Name, client.BaseURL)
+	}
+	hosts := c.remoteHosts(config)
+	sourceHosts := []string{hosts.Destination.Name}
+	for _, host := range hosts.Sources {
+		if !strings.EqualFold(host.Name, hosts.Destination.Name) {
+			sourceHosts = append(sourceHosts, host.Name)
+		}
+	}
+	fmt.Printf("  書き換え対象のリモートのホスト: %s\n", strings.Join(sourceHosts, ", "))
 	if config.Owner != "" {
 		fmt.Printf("  個人リポジトリ所有者: %s\n", config.Owner)
 	}
@@ -109,7 +433,50 @@ func (c *RewriteCommand) displayConfig(config *config.Config) {
 	if config.PushAll {
 		fmt.Printf("  全ブランチ・タグプッシュ: 有効\n")
 	}
-	fmt.Printf("  GitHub Actions制御: %s\n", map[bool]string{true: "プッシュ前に無効化、プッシュ後に有効化", false: "制御なし"}[config.DisableActions])
+	fmt.Printf("  プッシュ先リモート: %s\n", config.Remote)
+	if config.AllRemotes {
+		fmt.Printf("  全リモートのURL変更: 有効\n")
+	}
+	if config.NameTemplate != "" {
+		fmt.Printf("  リポジトリ名のテンプレート: %s\n", config.NameTemplate)
+	}
+	if config.CreateRemote {
+		fmt.Printf("  リモートがないリポジトリ: ディレクトリ名からリモートを追加\n")
+	}
+	if config.KeepOldRemote {
+		fmt.Printf("  移行前のリモート: %s として残す\n", config.OldRemoteName)
+	}
+	fmt.Printf("  書き換え先が衝突した場合: %s\n", config.OnCollision)
+	ciName := map[bool]string{true: "GitLab CI/CD", false: "GitHub Actions"}[config.Provider == "gitlab"]
+	restore := map[bool]string{true: "元の状態に復元", false: "有効化"}[config.Provider == "gitlab"]
+	fmt.Printf("  %s制御: %s\n", ciName, map[bool]string{true: "プッシュ前に無効化、プッシュ後に" + restore, false: "制御なし"}[config.DisableActions])
+	fmt.Printf("  未コミットの変更がある場合: %s\n", config.DirtyPolicy)
+	if config.Anonymize {
+		fmt.Printf("  contributorの匿名化: 有効（対応表: %s）\n", config.AnonymizeMap)
+	}
+	if config.SquashHistory {
+		fmt.Printf("  履歴の畳み込み: 1つのルートコミットにする\n")
+	} else if !config.TruncateBefore.IsZero() {
+		fmt.Printf("  履歴の畳み込み: %s より前\n", config.TruncateBefore.Format("2006-01-02 15:04:05"))
+	}
+	if normalization := c.treeNormalization(config); !normalization.IsEmpty() {
+		fmt.Printf("  ツリーの正規化: %s\n", normalization.String())
+	}
+	if config.ApplyGitignore {
+		fmt.Printf("  .gitignoreの履歴への適用: 有効\n")
+	}
+	if config.LFSMigration != nil {
+		fmt.Printf("  Git LFSへの移行: %s\n", config.LFSMigration.String())
+	}
+	if condition := c.commitCondition(config); !condition.IsEmpty() {
+		fmt.Printf("  書き換え対象コミット: %s\n", condition.String())
+	}
+	if config.Prune {
+		fmt.Printf("  書き換え後のクリーンアップ: 有効\n")
+		if config.BackupDir != "" {
+			fmt.Printf("  バックアップ先: %s\n", config.BackupDir)
+		}
+	}
 	fmt.Println()
 }
 
@@ -129,15 +496,103 @@ func (c *RewriteCommand) createRewriter(config *config.Config) *rewriter.Rewrite
 	}
 
 	// 設定をRewriterに適用
+	if config.Provider == "gitlab" {
+		gitRewriter.SetProvider(gitlab.NewClient(config.GitHubToken, c.destinationHost(config), config.GitHubAPIURL))
+	} else {
+		gitRewriter.SetProvider(c.githubClient(config))
+	}
+	gitRewriter.SetRemoteHosts(c.remoteHosts(config))
 	gitRewriter.SetPushAllOption(config.PushAll)
+	gitRewriter.SetRemoteOption(config.Remote, config.AllRemotes)
+	gitRewriter.SetCreateMissingRemote(config.CreateRemote)
+	if config.KeepOldRemote {
+		gitRewriter.SetOldRemoteName(config.OldRemoteName)
+	}
 	gitRewriter.SetOwnershipConfig(config.Owner, config.Organization)
 	gitRewriter.SetPrivateOption(config.Private)
 	gitRewriter.SetCollaboratorsFromString(config.Collaborators)
 	gitRewriter.SetDisableActionsOption(config.DisableActions)
+	gitRewriter.SetDirtyPolicy(config.DirtyPolicy)
+	gitRewriter.SetPruneOption(config.Prune, config.BackupDir)
+	gitRewriter.SetCommitCondition(c.commitCondition(config))
+	gitRewriter.SetHistoryTruncation(config.SquashHistory, config.TruncateBefore)
+	gitRewriter.SetTreeNormalization(c.treeNormalization(config))
+	gitRewriter.SetApplyGitignore(config.ApplyGitignore)
+	gitRewriter.SetLFSMigration(config.LFSMigration)
 
 	return gitRewriter
 }
 
+// commitCondition は設定から書き換え対象コミットの条件を作成する
+func (c *RewriteCommand) commitCondition(config *config.Config) git.CommitCondition {
+	return git.CommitCondition{
+		Since:    config.Since,
+		Until:    config.Until,
+		Branches: config.OnlyBranches,
+		Range:    config.CommitRange,
+	}
+}
+
+// treeNormalization は設定から履歴中のツリーに適用する正規化を作成する
+func (c *RewriteCommand) treeNormalization(config *config.Config) git.TreeNormalization {
+	return git.TreeNormalization{
+		EOL:                config.NormalizeEOL,
+		StripExecBits:      config.StripExecBits,
+		TrailingWhitespace: config.StripWhitespace,
+	}
+}
+
+// displayPreflight は作業ツリーの事前チェック結果を表示する
+func (c *RewriteCommand) displayPreflight(result *rewriter.RewriteResult) {
+	preflight := result.Preflight
+	if preflight == nil || preflight.Action == "clean" {
+		return
+	}
+
+	switch {
+	case preflight.Stashed && preflight.Restored:
+		fmt.Println("📦 作業ツリー: 自動stashし、処理後に復元しました")
+	case preflight.Stashed:
+		fmt.Printf("📦 作業ツリー: 自動stashしましたが復元に失敗しました: %v\n", preflight.RestoreError)
+	case preflight.Action == "continue":
+		fmt.Println("📦 作業ツリー: 未コミットの変更を残したまま続行しました")
+	case preflight.Action == "refuse":
+		fmt.Println("📦 作業ツリー: 未コミットの変更または進行中の操作があるため処理を中止しました")
+	}
+}
+
+// displaySizeReport はクリーンアップ前後のリポジトリサイズを表示する
+func (c *RewriteCommand) displaySizeReport(results []*rewriter.RewriteResult) {
+	var pruned []*rewriter.RewriteResult
+	for _, result := range results {
+		if result.Prune != nil || result.PruneError != nil {
+			pruned = append(pruned, result)
+		}
+	}
+	if len(pruned) == 0 {
+		return
+	}
+
+	fmt.Printf("\n=== クリーンアップ結果 ===\n")
+	var totalBefore, totalAfter int64
+	for _, result := range pruned {
+		if result.PruneError != nil || result.Prune.SizeAfter == 0 {
+			fmt.Printf("  - %s: クリーンアップ失敗 (%v)\n", result.GitDir, result.PruneError)
+			continue
+		}
+		totalBefore += result.Prune.SizeBefore
+		totalAfter += result.Prune.SizeAfter
+		fmt.Printf("  - %s: %s → %s\n", result.GitDir, utils.FormatBytes(result.Prune.SizeBefore), utils.FormatBytes(result.Prune.SizeAfter))
+		if !result.Prune.BackupRefsRemoved {
+			fmt.Println("    refs/original は保持されています（--backup-dir 未指定）")
+		}
+		if result.Prune.StashKept {
+			fmt.Println("    stashが残っているため、書き換え前のコミットは削除されていません（git stash list で確認してください）")
+		}
+	}
+	fmt.Printf("合計: %s → %s\n", utils.FormatBytes(totalBefore), utils.FormatBytes(totalAfter))
+}
+
 // displayResults は最終結果を表示する
 func (c *RewriteCommand) displayResults(successCount, totalCount int, failedRepos, pushFailedRepos []string) error {
 	fmt.Printf("\n=== 実行結果 ===\n")
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git-rewrite/pkg/cli/config"
+	"git-rewrite/pkg/rules"
+	"git-rewrite/pkg/utils"
+)
+
+// setupRemoteRepo はリモートを設定したコミット済みのリポジトリを作成する
+func setupRemoteRepo(t *testing.T, dir, remoteURL string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("ディレクトリ作成エラー: %v", err)
+	}
+	commands := [][]string{
+		{"git", "init"},
+		{"git", "config", "user.name", "Test User"},
+		{"git", "config", "user.email", "test@example.com"},
+		{"git", "commit", "--allow-empty", "-m", "initial"},
+		{"git", "remote", "add", "origin", remoteURL},
+	}
+	for _, args := range commands {
+		if _, stderr, err := utils.RunCommand(dir, args[0], args[1:]...); err != nil {
+			t.Fatalf("%v エラー: %v, stderr: %s", args, err, stderr)
+		}
+	}
+}
+
+// TestPlanBatchProgress はルールに一致したリポジトリ・名前を変更したリポジトリのRewriterにも全体の進捗表示が設定されることをテストする
+func TestPlanBatchProgress(t *testing.T) {
+	root := t.TempDir()
+	identityRepo := filepath.Join(root, "team", "app")
+	ownerRepo := filepath.Join(root, "tools", "cli")
+	renamedRepo := filepath.Join(root, "other", "app")
+	plainRepo := filepath.Join(root, "plain")
+	setupRemoteRepo(t, identityRepo, "https://github.com/team/app.git")
+	setupRemoteRepo(t, ownerRepo, "https://github.com/tools/cli.git")
+	setupRemoteRepo(t, renamedRepo, "https://github.com/other/app.git")
+	setupRemoteRepo(t, plainRepo, "https://github.com/someone/plain.git")
+	gitDirs := []string{identityRepo, ownerRepo, renamedRepo, plainRepo}
+
+	cfg, err := config.ParseRewriteArgs([]string{"ghp_test123", "--user", "testuser", "--email", "test@example.com",
+		"--target-dir", root, "--on-collision", "suffix"})
+	if err != nil {
+		t.Fatalf("引数解析エラー: %v", err)
+	}
+	identityRules := &rules.IdentityRules{Rules: []rules.IdentityRule{{Path: "team/*", Email: "team@example.com"}}}
+	ownerRules := &rules.OwnerRules{Rules: []rules.OwnerRule{{Path: "tools/*", Organization: "tools-org"}}}
+
+	c := NewRewriteCommand()
+	batch, err := c.planBatch(cfg, root, gitDirs, c.createRewriter(cfg), identityRules, ownerRules)
+	if err != nil {
+		t.Fatalf("planBatchでエラーが発生しました: %v", err)
+	}
+
+	if batch.progress == nil || batch.totalCommits != len(gitDirs) {
+		t.Fatalf("全体の進捗表示が作成されていません: progress=%v, totalCommits=%d", batch.progress, batch.totalCommits)
+	}
+	if batch.rewriters[identityRepo].GitHubEmail != "team@example.com" {
+		t.Errorf("identityルールが適用されていません: %s", batch.rewriters[identityRepo].GitHubEmail)
+	}
+	if batch.rewriters[ownerRepo].Organization != "tools-org" {
+		t.Errorf("所有者ルールが適用されていません: %s", batch.rewriters[ownerRepo].Organization)
+	}
+	if batch.rewriters[renamedRepo].RepoNameOverride == "" {
+		t.Error("書き換え先が衝突したリポジトリの名前が変更されていません")
+	}
+	for _, gitDir := range gitDirs {
+		if batch.rewriters[gitDir].HistoryOptions.Progress != batch.progress {
+			t.Errorf("%s のRewriterに全体の進捗表示が設定されていません", gitDir)
+		}
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
+
+	"git-rewrite/pkg/git"
 )
 
 // Config はアプリケーション全体の設定を保持する
@@ -12,14 +16,48 @@ type Config struct {
 	GitHubUser         string
 	GitHubEmail        string
 	TargetDir          string
+	Provider           string   // 書き換え先のホスティングサービス（github, gitlab）
+	GitHubHost         string   // GitHub Enterprise Server・GitLabのホスト（空の場合はgithub.com、GitLabの場合はgitlab.com）
+	GitHubAPIURL       string   // GitHub・GitLab APIのベースURL（空の場合はホストから決定）
+	SourceHosts        []string // 書き換え対象とするリモートのホスト（書き換え先のホストは常に対象）
 	Owner              string
 	Organization       string
 	Collaborators      string
 	CollaboratorConfig string
 	PushAll            bool
+	Remote             string // 確認・プッシュに使用するリモート（デフォルト: origin）
+	AllRemotes         bool   // GitHubを指す全リモートのURLを変更する
+	NameTemplate       string // 書き換え先のリポジトリ名のテンプレート（例: {org}-{repo}）
+	NameMap            string // 元のリポジトリ名と新しいリポジトリ名の対応表ファイル
+	CreateRemote       bool   // リモートがないリポジトリにディレクトリ名からリモートを追加する
+	KeepOldRemote      bool   // 移行前のURLをリモートとして残す
+	OldRemoteName      string // 移行前のURLを残すリモート名（{remote}は元のリモート名）
+	OnCollision        string // 書き換え先が同じリポジトリがある場合の処理方針（error, skip, suffix）
 	Debug              bool
 	Private            bool
-	DisableActions     bool // GitHub Actionsを無効化するかどうか（デフォルト: true）
+	DisableActions     bool              // GitHub Actionsを無効化するかどうか（デフォルト: true）
+	DirtyPolicy        string            // 作業ツリーが汚れている場合の処理方針（refuse, stash, continue）
+	Prune              bool              // 書き換え後にreflog・バックアップ参照を削除してgcを実行するかどうか
+	BackupDir          string            // クリーンアップ前のバックアップbundleの保存先
+	Since              time.Time         // この日時以降のコミットのみ書き換える
+	Until              time.Time         // この日時より前のコミットのみ書き換える
+	OnlyBranches       []string          // これらのブランチから到達可能なコミットのみ書き換える
+	CommitRange        string            // このコミット範囲（A..B）のみ書き換える
+	IdentityRules      string            // ディレクトリごとの書き換え先identityを定義したルールファイル
+	OwnerRules         string            // リポジトリを書き換え先の所有者に振り分けるルールファイル
+	SquashHistory      bool              // 履歴を現在のツリーのみを持つ1つのルートコミットにする
+	TruncateBefore     time.Time         // この日時より前の履歴を1つのルートコミットに畳み込む
+	Anonymize          bool              // 全contributorを決定的な仮名に置き換える
+	AnonymizeSalt      string            // 仮名生成に使用するソルト
+	AnonymizeMap       string            // 元のidentityと仮名の対応表を書き出すファイル
+	NoreplyEmail       bool              // GitHubのnoreplyメールアドレスを取得して書き換え先メールアドレスにする
+	EmailCheck         string            // メールアドレスがGitHubアカウントに登録済みか確認する方針（error, warn, off）
+	NormalizeEOL       bool              // .gitattributesに従って履歴中のCRLFをLFに正規化する
+	StripExecBits      bool              // 履歴中のシバンで始まらないファイルの実行権限を外す
+	StripWhitespace    []string          // 行末の空白を削除するファイルのglobパターン
+	ApplyGitignore     bool              // 現在のHEADの.gitignoreに一致するファイルを履歴全体から削除する
+	RefRules           string            // ブランチ・タグの名前変更ルールファイル
+	LFSMigration       *git.LFSMigration // Git LFSに移行するファイルの条件
 }
 
 // ParseRewriteArgs はrewriteコマンドの引数を解析する
@@ -34,7 +72,7 @@ func ParseRewriteArgs(args []string) (*Config, error) {
 		fmt.Println("")
 		fmt.Println("必須引数:")
 		fmt.Println("  --user, -u <username>           GitHubユーザー名")
-		fmt.Println("  --email, -e <email>             GitHubメールアドレス")
+		fmt.Println("  --email, -e <email>             GitHubメールアドレス（--noreply-email指定時は不要）")
 		fmt.Println("")
 		fmt.Println("オプション引数:")
 		fmt.Println("  --target-dir, -d <directory>    対象ディレクトリ（デフォルト: .）")
@@ -43,10 +81,45 @@ func ParseRewriteArgs(args []string) (*Config, error) {
 		fmt.Println("  --collaborators <list>          コラボレーター設定（例: user1:push,user2:admin）")
 		fmt.Println("  --collaborator-config, -c <file> コラボレーター設定ファイル")
 		fmt.Println("  --push-all                      全ブランチ・タグをプッシュ")
+		fmt.Println("  --github-host <host>            GitHub Enterprise Serverのホスト（例: ghe.corp、GITHUB_HOST環境変数でも指定可）")
+		fmt.Println("  --api-url <url>                 GitHub APIのベースURL（デフォルト: https://<host>/api/v3、GITHUB_API_URL環境変数でも指定可）")
+		fmt.Println("  --source-host <hosts>           書き換え対象とするリモートのホスト（カンマ区切り、デフォルト: github.com。書き換え先のホストは常に対象）")
+		fmt.Println("  --provider <provider>           書き換え先のホスティングサービス: github, gitlab（デフォルト: github）")
+		fmt.Println("  --host <host>                   --github-host の別名。GitLabのホストの指定にも使用（--provider gitlab のデフォルト: gitlab.com、APIのデフォルト: https://<host>/api/v4）")
+		fmt.Println("  --remote <name>                 URLの変更・プッシュに使用するリモート（デフォルト: origin）")
+		fmt.Println("  --all-remotes                   GitHubを指す全リモートのURLを変更する（プッシュは--remoteのリモートに行う）")
+		fmt.Println("  --name-template <template>      書き換え先のリポジトリ名のテンプレート（例: '{org}-{repo}', 'legacy-{repo}'）")
+		fmt.Println("  --name-map <file>               元のリポジトリ名と新しいリポジトリ名の対応表（JSON、テンプレートより優先）")
+		fmt.Println("  --create-remote                 リモートがないリポジトリにディレクトリ名からリモートを追加してプッシュする")
+		fmt.Println("  --keep-old-remote               移行前のURLをリモートとして残し、移行の記録をgit configに保存する")
+		fmt.Println("  --old-remote-name <name>        移行前のURLを残すリモート名（デフォルト: old-{remote}、指定すると--keep-old-remoteも有効）")
+		fmt.Println("  --on-collision <strategy>       書き換え先が同じリポジトリがある場合の処理: error（中止）, skip（衝突したリポジトリを処理しない）, suffix（-2, -3 ... を付ける）（デフォルト: error）")
 		fmt.Println("  --debug                         デバッグモード")
 		fmt.Println("  --public                        パブリックリポジトリとして作成（デフォルト: プライベート）")
 		fmt.Println("  --disable-actions               プッシュ前にGitHub Actionsを無効化（プッシュ後に有効化）")
 		fmt.Println("  --enable-actions                GitHub Actions制御を無効化（デフォルトでActions制御は有効）")
+		fmt.Println("  --dirty-policy <policy>         未コミットの変更や進行中の操作がある場合の処理（refuse, stash, continue。デフォルト: refuse）")
+		fmt.Println("  --prune                         書き換え後にreflogとバックアップ参照を削除してgcを実行")
+		fmt.Println("  --backup-dir <directory>        --prune時にバックアップbundleを保存するディレクトリ")
+		fmt.Println("  --since <date>                  この日時以降のコミットのみ書き換える（例: 2024-04-01）")
+		fmt.Println("  --until <date>                  この日時より前のコミットのみ書き換える（例: 2024-04-01）")
+		fmt.Println("  --only-branches <list>          指定ブランチから到達可能なコミットのみ書き換える（例: main,develop）")
+		fmt.Println("  --commit-range <A..B>           指定範囲のコミットのみ書き換える")
+		fmt.Println("  --identity-rules <file>         ディレクトリごとの書き換え先identityを定義したJSONファイル")
+		fmt.Println("  --owner-rules <file>            元のオーナー・パス・リポジトリ名ごとに書き換え先の所有者を振り分けるJSONファイル")
+		fmt.Println("  --squash-history                履歴を現在のツリーのみを持つ1つのルートコミットにする")
+		fmt.Println("  --truncate-before <date>        指定日時より前の履歴を1つのルートコミットに畳み込む")
+		fmt.Println("  --anonymize                     全contributorを決定的な仮名（contributor-xxxxxxxx）に置き換える")
+		fmt.Println("  --anonymize-salt <salt>         仮名生成に使用するソルト（必須。GIT_REWRITE_ANONYMIZE_SALTでも指定可）")
+		fmt.Println("  --anonymize-map <file>          仮名の対応表の書き出し先（デフォルト: <ユーザー設定ディレクトリ>/git-rewrite/anonymization-map.json）")
+		fmt.Println("  --noreply-email                 トークンのユーザーのnoreplyメールアドレス（ID+login@users.noreply.github.com）で書き換える")
+		fmt.Println("  --email-check <policy>          メールアドレスがGitHubアカウントに登録・確認済みでない場合の処理（error, warn, off。デフォルト: error）")
+		fmt.Println("  --normalize-eol                 現在の.gitattributesに従って履歴中のCRLFをLFに正規化する")
+		fmt.Println("  --strip-exec-bits               履歴中のシバン（#!）で始まらないファイルの実行権限を外す")
+		fmt.Println("  --strip-trailing-whitespace <globs> 履歴中の一致するテキストファイルの行末の空白を削除する（カンマ区切り、例: '*.go,*.md'）")
+		fmt.Println("  --apply-gitignore               現在の.gitignoreに一致するファイルを全コミットから削除し、空になったコミットを除く")
+		fmt.Println("  --ref-rules <file>              ブランチ・タグの名前変更ルールを定義したJSONファイル")
+		fmt.Println("  --lfs-migrate <glob|size>       一致するファイル（例: '*.psd,*.zip'）または指定サイズ（例: 100MB）を超えるファイルを履歴全体でGit LFSに移行する")
 	}
 
 	config := &Config{
@@ -54,6 +127,8 @@ func ParseRewriteArgs(args []string) (*Config, error) {
 		TargetDir:      ".",
 		Private:        true, // デフォルトはプライベート
 		DisableActions: true, // デフォルトでActions制御を有効
+		DirtyPolicy:    git.DirtyPolicyRefuse,
+		EmailCheck:     "error",
 	}
 
 	// フラグ定義
@@ -70,7 +145,48 @@ func ParseRewriteArgs(args []string) (*Config, error) {
 	fs.StringVar(&config.CollaboratorConfig, "collaborator-config", "", "コラボレーター設定ファイル")
 	fs.StringVar(&config.CollaboratorConfig, "c", "", "コラボレーター設定ファイル")
 	fs.BoolVar(&config.PushAll, "push-all", false, "全ブランチ・タグをプッシュ")
+	fs.StringVar(&config.Provider, "provider", "github", "書き換え先のホスティングサービス")
+	fs.StringVar(&config.GitHubHost, "github-host", "", "GitHub Enterprise Serverのホスト")
+	fs.StringVar(&config.GitHubHost, "host", "", "GitHub Enterprise Server・GitLabのホスト")
+	fs.StringVar(&config.GitHubAPIURL, "api-url", "", "GitHub APIのベースURL")
+	var sourceHosts string
+	fs.StringVar(&sourceHosts, "source-host", "github.com", "書き換え対象とするリモートのホスト")
+	fs.StringVar(&config.Remote, "remote", git.DefaultRemote, "使用するリモート")
+	fs.BoolVar(&config.AllRemotes, "all-remotes", false, "全リモートのURLを変更する")
+	fs.StringVar(&config.NameTemplate, "name-template", "", "リポジトリ名のテンプレート")
+	fs.StringVar(&config.NameMap, "name-map", "", "リポジトリ名の対応表ファイル")
+	fs.BoolVar(&config.CreateRemote, "create-remote", false, "リモートがないリポジトリにリモートを追加する")
+	fs.BoolVar(&config.KeepOldRemote, "keep-old-remote", false, "移行前のURLをリモートとして残す")
+	fs.StringVar(&config.OldRemoteName, "old-remote-name", git.DefaultOldRemoteName, "移行前のURLを残すリモート名")
+	fs.StringVar(&config.OnCollision, "on-collision", "error", "書き換え先が衝突した場合の処理方針")
 	fs.BoolVar(&config.Debug, "debug", false, "デバッグモード")
+	fs.StringVar(&config.DirtyPolicy, "dirty-policy", git.DirtyPolicyRefuse, "作業ツリーが汚れている場合の処理方針")
+	fs.BoolVar(&config.Prune, "prune", false, "書き換え後のクリーンアップ")
+	fs.StringVar(&config.BackupDir, "backup-dir", "", "バックアップbundleの保存先")
+	fs.StringVar(&config.CommitRange, "commit-range", "", "書き換えるコミット範囲")
+	fs.StringVar(&config.IdentityRules, "identity-rules", "", "ディレクトリごとのidentityルールファイル")
+	fs.StringVar(&config.OwnerRules, "owner-rules", "", "書き換え先の所有者の振り分けルールファイル")
+	fs.BoolVar(&config.SquashHistory, "squash-history", false, "履歴を1つのルートコミットにする")
+	fs.BoolVar(&config.Anonymize, "anonymize", false, "全contributorを仮名に置き換える")
+	fs.StringVar(&config.AnonymizeSalt, "anonymize-salt", "", "仮名生成に使用するソルト")
+	fs.StringVar(&config.AnonymizeMap, "anonymize-map", "", "仮名の対応表の書き出し先")
+	fs.BoolVar(&config.NoreplyEmail, "noreply-email", false, "noreplyメールアドレスで書き換える")
+	fs.StringVar(&config.EmailCheck, "email-check", "error", "メールアドレスの登録確認の方針")
+	fs.BoolVar(&config.NormalizeEOL, "normalize-eol", false, "CRLFをLFに正規化する")
+	fs.BoolVar(&config.StripExecBits, "strip-exec-bits", false, "不要な実行権限を外す")
+	var stripWhitespace string
+	fs.StringVar(&stripWhitespace, "strip-trailing-whitespace", "", "行末の空白を削除するファイルのglobパターン")
+	fs.BoolVar(&config.ApplyGitignore, "apply-gitignore", false, ".gitignoreを履歴全体に適用する")
+	fs.StringVar(&config.RefRules, "ref-rules", "", "ブランチ・タグの名前変更ルールファイル")
+
+	// 書き換え対象の日時・ブランチ条件
+	var since, until, onlyBranches, truncateBefore string
+	fs.StringVar(&since, "since", "", "この日時以降のコミットのみ書き換える")
+	fs.StringVar(&until, "until", "", "この日時より前のコミットのみ書き換える")
+	fs.StringVar(&onlyBranches, "only-branches", "", "指定ブランチから到達可能なコミットのみ書き換える")
+	fs.StringVar(&truncateBefore, "truncate-before", "", "指定日時より前の履歴を畳み込む")
+	var lfsMigrate string
+	fs.StringVar(&lfsMigrate, "lfs-migrate", "", "Git LFSに移行するファイルのパターンまたはサイズ")
 
 	// Actions制御のオプション（デフォルトは有効）
 	var enableActions bool
@@ -85,6 +201,76 @@ func ParseRewriteArgs(args []string) (*Config, error) {
 		return nil, err
 	}
 
+	if config.NoreplyEmail && config.GitHubEmail != "" {
+		return nil, fmt.Errorf("--noreply-email と --email は同時に指定できません")
+	}
+	switch config.Provider {
+	case "github":
+	case "gitlab":
+		// noreplyメールアドレスはGitHubのアカウント情報から取得するため使用できない
+		if config.NoreplyEmail {
+			return nil, fmt.Errorf("--noreply-email は --provider gitlab と同時に指定できません")
+		}
+	default:
+		return nil, fmt.Errorf("--provider には github, gitlab のいずれかを指定してください: %s", config.Provider)
+	}
+
+	// 書き換え対象の条件を解析
+	if since != "" {
+		t, err := git.ParseDate(since)
+		if err != nil {
+			return nil, fmt.Errorf("--since: %v", err)
+		}
+		config.Since = t
+	}
+	if until != "" {
+		t, err := git.ParseDate(until)
+		if err != nil {
+			return nil, fmt.Errorf("--until: %v", err)
+		}
+		config.Until = t
+	}
+	if !config.Since.IsZero() && !config.Until.IsZero() && !config.Since.Before(config.Until) {
+		return nil, fmt.Errorf("--since は --until より前の日時を指定してください")
+	}
+	if truncateBefore != "" {
+		if config.SquashHistory {
+			return nil, fmt.Errorf("--squash-history と --truncate-before は同時に指定できません")
+		}
+		t, err := git.ParseDate(truncateBefore)
+		if err != nil {
+			return nil, fmt.Errorf("--truncate-before: %v", err)
+		}
+		config.TruncateBefore = t
+	}
+	if lfsMigrate != "" {
+		migration, err := git.ParseLFSMigration(lfsMigrate)
+		if err != nil {
+			return nil, fmt.Errorf("--lfs-migrate: %v", err)
+		}
+		config.LFSMigration = migration
+	}
+	for _, host := range strings.Split(sourceHosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			config.SourceHosts = append(config.SourceHosts, host)
+		}
+	}
+	for _, pattern := range strings.Split(stripWhitespace, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			config.StripWhitespace = append(config.StripWhitespace, pattern)
+		}
+	}
+	for _, branch := range strings.Split(onlyBranches, ",") {
+		if branch = strings.TrimSpace(branch); branch != "" {
+			config.OnlyBranches = append(config.OnlyBranches, branch)
+		}
+	}
+
+	// --old-remote-nameが指定された場合は移行前のリモートを残す
+	if config.OldRemoteName != git.DefaultOldRemoteName {
+		config.KeepOldRemote = true
+	}
+
 	// --enable-actionsが指定された場合はActions制御を無効にする
 	if enableActions {
 		config.DisableActions = false
@@ -97,10 +283,19 @@ func ParseRewriteArgs(args []string) (*Config, error) {
 
 	// 環境変数からのフォールバック（後方互換性）
 	config.GitHubUser = getConfigValue(config.GitHubUser, "GITHUB_USER", "")
-	config.GitHubEmail = getConfigValue(config.GitHubEmail, "GITHUB_EMAIL", "")
+	if !config.NoreplyEmail {
+		// --noreply-email 指定時はメールアドレスをGitHub APIから取得する
+		config.GitHubEmail = getConfigValue(config.GitHubEmail, "GITHUB_EMAIL", "")
+	}
 	config.Owner = getConfigValue(config.Owner, "GITHUB_REPOSITORY_OWNER", "")
 	config.Organization = getConfigValue(config.Organization, "GITHUB_ORGANIZATION", "")
 	config.Collaborators = getConfigValue(config.Collaborators, "GITHUB_COLLABORATORS", "")
+	config.AnonymizeSalt = getConfigValue(config.AnonymizeSalt, "GIT_REWRITE_ANONYMIZE_SALT", "")
+	config.GitHubHost = getConfigValue(config.GitHubHost, "GITHUB_HOST", "")
+	config.GitHubAPIURL = getConfigValue(config.GitHubAPIURL, "GITHUB_API_URL", "")
+	if config.Provider == "gitlab" && config.GitHubHost == "" {
+		config.GitHubHost = "gitlab.com"
+	}
 
 	// デバッグモードの環境変数チェック
 	if !config.Debug && os.Getenv("GIT_REWRITE_DEBUG") != "" {
@@ -111,9 +306,56 @@ func ParseRewriteArgs(args []string) (*Config, error) {
 	if config.GitHubUser == "" {
 		return nil, fmt.Errorf("--user フラグまたはGITHUB_USER環境変数が必要です")
 	}
-	if config.GitHubEmail == "" {
+	if config.GitHubEmail == "" && !config.NoreplyEmail {
 		return nil, fmt.Errorf("--email フラグまたはGITHUB_EMAIL環境変数が必要です")
 	}
+	if config.Anonymize && config.AnonymizeSalt == "" {
+		return nil, fmt.Errorf("--anonymize には --anonymize-salt フラグまたはGIT_REWRITE_ANONYMIZE_SALT環境変数が必要です")
+	}
+	if config.Anonymize && config.AnonymizeMap == "" {
+		// 書き換え対象のリポジトリにコミットされないよう、カレントディレクトリではなく設定ディレクトリに書き出す
+		mapPath, err := git.DefaultAnonymizeMapPath()
+		if err != nil {
+			return nil, err
+		}
+		config.AnonymizeMap = mapPath
+	}
+	if config.GitHubAPIURL != "" && config.GitHubHost == "" {
+		return nil, fmt.Errorf("--api-url を指定する場合は --github-host（--host）も指定してください")
+	}
+	if config.GitHubAPIURL != "" && !strings.HasPrefix(config.GitHubAPIURL, "https://") && !strings.HasPrefix(config.GitHubAPIURL, "http://") {
+		return nil, fmt.Errorf("--api-url にはhttps://から始まるURLを指定してください: %s", config.GitHubAPIURL)
+	}
+	if strings.TrimSpace(config.Remote) == "" {
+		return nil, fmt.Errorf("--remote にはリモート名を指定してください")
+	}
+	if config.KeepOldRemote {
+		if strings.TrimSpace(config.OldRemoteName) == "" {
+			return nil, fmt.Errorf("--old-remote-name にはリモート名を指定してください")
+		}
+		if config.AllRemotes && !strings.Contains(config.OldRemoteName, "{remote}") {
+			return nil, fmt.Errorf("--all-remotes と併用する場合、--old-remote-name には {remote} を含めてください: %s", config.OldRemoteName)
+		}
+		if git.OldRemoteName(config.OldRemoteName, config.Remote) == config.Remote {
+			return nil, fmt.Errorf("--old-remote-name には --remote と異なる名前を指定してください: %s", config.OldRemoteName)
+		}
+	}
+	if !git.IsValidDirtyPolicy(config.DirtyPolicy) {
+		return nil, fmt.Errorf("--dirty-policy には %s, %s, %s のいずれかを指定してください: %s", git.DirtyPolicyRefuse, git.DirtyPolicyStash, git.DirtyPolicyContinue, config.DirtyPolicy)
+	}
+	if config.BackupDir != "" && !config.Prune {
+		return nil, fmt.Errorf("--backup-dir を指定する場合は --prune も指定してください")
+	}
+	switch config.OnCollision {
+	case "error", "skip", "suffix":
+	default:
+		return nil, fmt.Errorf("--on-collision には error, skip, suffix のいずれかを指定してください: %s", config.OnCollision)
+	}
+	switch config.EmailCheck {
+	case "error", "warn", "off":
+	default:
+		return nil, fmt.Errorf("--email-check には error, warn, off のいずれかを指定してください: %s", config.EmailCheck)
+	}
 
 	return config, nil
 }
@@ -128,3 +370,46 @@ func getConfigValue(flagValue, envKey, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// IdentitiesConfig はidentitiesコマンドの設定を保持する
+type IdentitiesConfig struct {
+	TargetDir string
+	Format    string // 出力形式（table, csv, json）
+	Output    string // 出力先ファイル（空の場合は標準出力）
+}
+
+// ParseIdentitiesArgs はidentitiesコマンドの引数を解析する
+func ParseIdentitiesArgs(args []string) (*IdentitiesConfig, error) {
+	fs := flag.NewFlagSet("identities", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Println("使用方法: git-rewrite identities [options]")
+		fmt.Println("")
+		fmt.Println("オプション引数:")
+		fmt.Println("  --target-dir, -d <directory>    対象ディレクトリ（デフォルト: .）")
+		fmt.Println("  --format, -f <format>           出力形式（table, csv, json。デフォルト: table）")
+		fmt.Println("  --output <file>                 出力先ファイル（デフォルト: 標準出力）")
+	}
+
+	config := &IdentitiesConfig{
+		TargetDir: ".",
+		Format:    "table",
+	}
+
+	fs.StringVar(&config.TargetDir, "target-dir", ".", "対象ディレクトリ")
+	fs.StringVar(&config.TargetDir, "d", ".", "対象ディレクトリ")
+	fs.StringVar(&config.Format, "format", "table", "出力形式")
+	fs.StringVar(&config.Format, "f", "table", "出力形式")
+	fs.StringVar(&config.Output, "output", "", "出力先ファイル")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	switch config.Format {
+	case "table", "csv", "json":
+	default:
+		return nil, fmt.Errorf("--format には table, csv, json のいずれかを指定してください: %s", config.Format)
+	}
+
+	return config, nil
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -204,6 +206,9 @@ func TestParseRewriteArgsDefaults(t *testing.T) {
 	if !config.DisableActions {
 		t.Error("DisableActionsのデフォルト値がtrueではありません")
 	}
+	if config.DirtyPolicy != "refuse" {
+		t.Errorf("DirtyPolicyのデフォルト値が正しくありません。期待値: refuse, 実際: %s", config.DirtyPolicy)
+	}
 }
 
 // TestParseRewriteArgsActionsControl はActions制御オプションをテストする
@@ -247,6 +252,454 @@ func TestParseRewriteArgsActionsControl(t *testing.T) {
 	}
 }
 
+// TestParseRewriteArgsDirtyPolicy は--dirty-policyオプションをテストする
+func TestParseRewriteArgsDirtyPolicy(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	for _, policy := range []string{"refuse", "stash", "continue"} {
+		config, err := ParseRewriteArgs(append(base, "--dirty-policy", policy))
+		if err != nil {
+			t.Errorf("--dirty-policy %s でエラーが発生しました: %v", policy, err)
+			continue
+		}
+		if config.DirtyPolicy != policy {
+			t.Errorf("DirtyPolicyが期待値と異なります。期待値: %s, 実際: %s", policy, config.DirtyPolicy)
+		}
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--dirty-policy", "ignore")); err == nil {
+		t.Error("無効な--dirty-policyでエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsPrune は --prune と --backup-dir の解析をテストする
+func TestParseRewriteArgsPrune(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(append(base, "--prune", "--backup-dir", "/tmp/backups"))
+	if err != nil {
+		t.Fatalf("--prune --backup-dir でエラーが発生しました: %v", err)
+	}
+	if !config.Prune || config.BackupDir != "/tmp/backups" {
+		t.Errorf("--prune と --backup-dir が正しく解析されていません: Prune=%v, BackupDir=%s", config.Prune, config.BackupDir)
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--backup-dir", "/tmp/backups")); err == nil {
+		t.Error("--prune なしの --backup-dir でエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsCommitCondition は書き換え対象コミットの条件オプションをテストする
+func TestParseRewriteArgsCommitCondition(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(append(base, "--since", "2020-01-01", "--until", "2024-04-01",
+		"--only-branches", "main, develop", "--commit-range", "v1.0..v2.0"))
+	if err != nil {
+		t.Fatalf("条件オプションの解析でエラーが発生しました: %v", err)
+	}
+	if config.Since.Year() != 2020 || config.Until.Year() != 2024 {
+		t.Errorf("日時条件が正しく解析されていません: since=%v, until=%v", config.Since, config.Until)
+	}
+	if len(config.OnlyBranches) != 2 || config.OnlyBranches[0] != "main" || config.OnlyBranches[1] != "develop" {
+		t.Errorf("ブランチ条件が正しく解析されていません: %v", config.OnlyBranches)
+	}
+	if config.CommitRange != "v1.0..v2.0" {
+		t.Errorf("コミット範囲が正しく解析されていません: %s", config.CommitRange)
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--until", "2024/04/01")); err == nil {
+		t.Error("無効な日付でエラーが期待されました")
+	}
+	if _, err := ParseRewriteArgs(append(base, "--since", "2024-04-01", "--until", "2020-01-01")); err == nil {
+		t.Error("--sinceが--until以降の場合はエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsHistoryTruncation は履歴の畳み込みオプションをテストする
+func TestParseRewriteArgsHistoryTruncation(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(append(base, "--squash-history"))
+	if err != nil || !config.SquashHistory {
+		t.Errorf("--squash-history が正しく解析されていません: %v", err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--truncate-before", "2023-01-01"))
+	if err != nil || config.TruncateBefore.Year() != 2023 {
+		t.Errorf("--truncate-before が正しく解析されていません: %v", err)
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--squash-history", "--truncate-before", "2023-01-01")); err == nil {
+		t.Error("--squash-history と --truncate-before の同時指定でエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsAnonymize は匿名化オプションをテストする
+func TestParseRewriteArgsAnonymize(t *testing.T) {
+	// 環境変数をクリーンアップ
+	originalSalt := os.Getenv("GIT_REWRITE_ANONYMIZE_SALT")
+	defer restoreEnv("GIT_REWRITE_ANONYMIZE_SALT", originalSalt)
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	if _, err := ParseRewriteArgs(append(base, "--anonymize")); err == nil {
+		t.Error("ソルトなしの--anonymizeでエラーが期待されました")
+	}
+
+	config, err := ParseRewriteArgs(append(base, "--anonymize", "--anonymize-salt", "secret"))
+	if err != nil {
+		t.Fatalf("--anonymize の解析でエラーが発生しました: %v", err)
+	}
+	if !config.Anonymize || config.AnonymizeSalt != "secret" {
+		t.Errorf("匿名化オプションが正しく解析されていません: %+v", config)
+	}
+	// デフォルトの対応表はカレントディレクトリ（書き換え対象になり得る）に置かない
+	if !filepath.IsAbs(config.AnonymizeMap) || filepath.Base(filepath.Dir(config.AnonymizeMap)) != "git-rewrite" {
+		t.Errorf("対応表のデフォルトの書き出し先が設定ディレクトリ配下ではありません: %s", config.AnonymizeMap)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--anonymize", "--anonymize-salt", "secret", "--anonymize-map", "/tmp/map.json"))
+	if err != nil || config.AnonymizeMap != "/tmp/map.json" {
+		t.Errorf("--anonymize-map が使用されていません: %v, %s", err, config.AnonymizeMap)
+	}
+
+	os.Setenv("GIT_REWRITE_ANONYMIZE_SALT", "envsalt")
+	config, err = ParseRewriteArgs(append(base, "--anonymize"))
+	if err != nil || config.AnonymizeSalt != "envsalt" {
+		t.Errorf("環境変数のソルトが使用されていません: %v", err)
+	}
+}
+
+// TestParseRewriteArgsNoreplyEmail は--noreply-emailオプションをテストする
+func TestParseRewriteArgsNoreplyEmail(t *testing.T) {
+	// 環境変数をクリーンアップ
+	originalEmail := os.Getenv("GITHUB_EMAIL")
+	defer restoreEnv("GITHUB_EMAIL", originalEmail)
+	clearTestEnvs()
+
+	config, err := ParseRewriteArgs([]string{"ghp_test123", "--user", "testuser", "--noreply-email"})
+	if err != nil {
+		t.Fatalf("--noreply-email 指定時に--emailなしでエラーが発生しました: %v", err)
+	}
+	if !config.NoreplyEmail || config.GitHubEmail != "" {
+		t.Errorf("--noreply-email が正しく解析されていません: %+v", config)
+	}
+
+	// 環境変数のメールアドレスは使用されない
+	os.Setenv("GITHUB_EMAIL", "env@example.com")
+	config, err = ParseRewriteArgs([]string{"ghp_test123", "--user", "testuser", "--noreply-email"})
+	if err != nil || config.GitHubEmail != "" {
+		t.Errorf("--noreply-email 指定時に環境変数のメールアドレスが使用されました: %v", err)
+	}
+
+	if _, err := ParseRewriteArgs([]string{"ghp_test123", "--user", "testuser", "--email", "test@example.com", "--noreply-email"}); err == nil {
+		t.Error("--email と --noreply-email の同時指定でエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsEmailCheck は--email-checkオプションをテストする
+func TestParseRewriteArgsEmailCheck(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(base)
+	if err != nil || config.EmailCheck != "error" {
+		t.Errorf("--email-check のデフォルト値が正しくありません: %v", err)
+	}
+
+	for _, policy := range []string{"error", "warn", "off"} {
+		config, err := ParseRewriteArgs(append(base, "--email-check", policy))
+		if err != nil || config.EmailCheck != policy {
+			t.Errorf("--email-check %s が正しく解析されていません: %v", policy, err)
+		}
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--email-check", "skip")); err == nil {
+		t.Error("無効な--email-checkでエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsTreeNormalization はツリーの正規化オプションをテストする
+func TestParseRewriteArgsTreeNormalization(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(base)
+	if err != nil || config.NormalizeEOL || config.StripExecBits || len(config.StripWhitespace) != 0 {
+		t.Errorf("正規化はデフォルトで無効であることが期待されました: %v", err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--normalize-eol", "--strip-exec-bits", "--strip-trailing-whitespace", "*.go, docs/*.md"))
+	if err != nil {
+		t.Fatalf("正規化オプションの解析でエラーが発生しました: %v", err)
+	}
+	if !config.NormalizeEOL || !config.StripExecBits || strings.Join(config.StripWhitespace, ",") != "*.go,docs/*.md" {
+		t.Errorf("正規化オプションが正しく解析されていません: %+v", config)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--apply-gitignore"))
+	if err != nil || !config.ApplyGitignore {
+		t.Errorf("--apply-gitignore が正しく解析されていません: %v", err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--ref-rules", "refs.json"))
+	if err != nil || config.RefRules != "refs.json" {
+		t.Errorf("--ref-rules が正しく解析されていません: %v", err)
+	}
+}
+
+// TestParseRewriteArgsLFSMigrate は --lfs-migrate の解析をテストする
+func TestParseRewriteArgsLFSMigrate(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(base)
+	if err != nil || config.LFSMigration != nil {
+		t.Errorf("LFSへの移行はデフォルトで無効であることが期待されました: %v", err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--lfs-migrate", "100MB"))
+	if err != nil || config.LFSMigration == nil || config.LFSMigration.Above != "100mb" {
+		t.Errorf("--lfs-migrate のサイズ指定が正しく解析されていません: %+v, %v", config, err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--lfs-migrate", "*.psd,*.zip"))
+	if err != nil || config.LFSMigration == nil || len(config.LFSMigration.Patterns) != 2 {
+		t.Errorf("--lfs-migrate のパターン指定が正しく解析されていません: %v", err)
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--lfs-migrate", "*.psd,100MB")); err == nil {
+		t.Error("パターンとサイズの同時指定でエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsRemote は --remote と --all-remotes の解析をテストする
+func TestParseRewriteArgsRemote(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(base)
+	if err != nil || config.Remote != "origin" || config.AllRemotes {
+		t.Errorf("デフォルトはoriginのみであることが期待されました: %+v, %v", config, err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--remote", "upstream", "--all-remotes"))
+	if err != nil || config.Remote != "upstream" || !config.AllRemotes {
+		t.Errorf("--remote と --all-remotes が正しく解析されていません: %+v, %v", config, err)
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--remote", "")); err == nil {
+		t.Error("空のリモート名でエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsCreateRemote は --create-remote の解析をテストする
+func TestParseRewriteArgsCreateRemote(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(base)
+	if err != nil || config.CreateRemote {
+		t.Errorf("デフォルトではリモートを追加しないことが期待されました: %+v, %v", config, err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--create-remote"))
+	if err != nil || !config.CreateRemote {
+		t.Errorf("--create-remote が正しく解析されていません: %+v, %v", config, err)
+	}
+}
+
+// TestParseRewriteArgsKeepOldRemote は --keep-old-remote と --old-remote-name の解析をテストする
+func TestParseRewriteArgsKeepOldRemote(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(base)
+	if err != nil || config.KeepOldRemote {
+		t.Errorf("デフォルトでは移行前のリモートを残さないことが期待されました: %+v, %v", config, err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--keep-old-remote"))
+	if err != nil || !config.KeepOldRemote || config.OldRemoteName != "old-{remote}" {
+		t.Errorf("--keep-old-remote が正しく解析されていません: %+v, %v", config, err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--old-remote-name", "legacy"))
+	if err != nil || !config.KeepOldRemote || config.OldRemoteName != "legacy" {
+		t.Errorf("--old-remote-name の指定で移行前のリモートを残すことが期待されました: %+v, %v", config, err)
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--old-remote-name", "legacy", "--all-remotes")); err == nil {
+		t.Error("--all-remotes と {remote} を含まない --old-remote-name でエラーが期待されました")
+	}
+	if _, err := ParseRewriteArgs(append(base, "--old-remote-name", "origin")); err == nil {
+		t.Error("--remote と同じ --old-remote-name でエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsOnCollision は --on-collision の解析をテストする
+func TestParseRewriteArgsOnCollision(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(base)
+	if err != nil || config.OnCollision != "error" {
+		t.Errorf("デフォルトはerrorであることが期待されました: %+v, %v", config, err)
+	}
+
+	for _, strategy := range []string{"skip", "suffix"} {
+		config, err := ParseRewriteArgs(append(base, "--on-collision", strategy))
+		if err != nil || config.OnCollision != strategy {
+			t.Errorf("--on-collision %s が正しく解析されていません: %+v, %v", strategy, config, err)
+		}
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--on-collision", "overwrite")); err == nil {
+		t.Error("不明な処理方針でエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsOwnerRules は --owner-rules の解析をテストする
+func TestParseRewriteArgsOwnerRules(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	config, err := ParseRewriteArgs([]string{"ghp_test123", "--user", "testuser", "--email", "test@example.com", "--owner-rules", "owners.json"})
+	if err != nil || config.OwnerRules != "owners.json" {
+		t.Errorf("--owner-rules が正しく解析されていません: %+v, %v", config, err)
+	}
+}
+
+// TestParseRewriteArgsProvider は --provider と --host の解析をテストする
+func TestParseRewriteArgsProvider(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"glpat_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(base)
+	if err != nil || config.Provider != "github" || config.GitHubHost != "" {
+		t.Errorf("デフォルトはGitHubであることが期待されました: %+v, %v", config, err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--provider", "gitlab", "--host", "gitlab.example.com"))
+	if err != nil || config.Provider != "gitlab" || config.GitHubHost != "gitlab.example.com" {
+		t.Errorf("--provider と --host が正しく解析されていません: %+v, %v", config, err)
+	}
+
+	config, err = ParseRewriteArgs(append(base, "--provider", "gitlab"))
+	if err != nil || config.GitHubHost != "gitlab.com" {
+		t.Errorf("--host なしのGitLabはgitlab.comであることが期待されました: %+v, %v", config, err)
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--provider", "bitbucket")); err == nil {
+		t.Error("不明な --provider でエラーが期待されました")
+	}
+	if _, err := ParseRewriteArgs([]string{"glpat_test123", "--user", "testuser", "--provider", "gitlab", "--noreply-email"}); err == nil {
+		t.Error("--provider gitlab と --noreply-email の併用でエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsGitHubHost は --github-host と --api-url の解析をテストする
+func TestParseRewriteArgsGitHubHost(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(append(base, "--github-host", "ghe.corp", "--api-url", "https://ghe.corp/api/v3"))
+	if err != nil || config.GitHubHost != "ghe.corp" || config.GitHubAPIURL != "https://ghe.corp/api/v3" {
+		t.Errorf("--github-host と --api-url が正しく解析されていません: %+v, %v", config, err)
+	}
+
+	os.Setenv("GITHUB_HOST", "ghe.example.com")
+	config, err = ParseRewriteArgs(base)
+	os.Unsetenv("GITHUB_HOST")
+	if err != nil || config.GitHubHost != "ghe.example.com" {
+		t.Errorf("GITHUB_HOST環境変数が使用されていません: %v", err)
+	}
+
+	config, err = ParseRewriteArgs(base)
+	if err != nil || len(config.SourceHosts) != 1 || config.SourceHosts[0] != "github.com" {
+		t.Errorf("書き換え対象のホストのデフォルトはgithub.comであることが期待されました: %v, %v", config.SourceHosts, err)
+	}
+	config, err = ParseRewriteArgs(append(base, "--source-host", "github.com, ghe.old.corp"))
+	if err != nil || len(config.SourceHosts) != 2 || config.SourceHosts[1] != "ghe.old.corp" {
+		t.Errorf("--source-host が正しく解析されていません: %v, %v", config.SourceHosts, err)
+	}
+
+	if _, err := ParseRewriteArgs(append(base, "--api-url", "https://ghe.corp/api/v3")); err == nil {
+		t.Error("--github-host なしの --api-url でエラーが期待されました")
+	}
+	if _, err := ParseRewriteArgs(append(base, "--github-host", "ghe.corp", "--api-url", "ghe.corp/api/v3")); err == nil {
+		t.Error("スキームのない --api-url でエラーが期待されました")
+	}
+}
+
+// TestParseRewriteArgsNameTemplate は --name-template と --name-map の解析をテストする
+func TestParseRewriteArgsNameTemplate(t *testing.T) {
+	// 環境変数をクリーンアップ
+	clearTestEnvs()
+
+	base := []string{"ghp_test123", "--user", "testuser", "--email", "test@example.com"}
+
+	config, err := ParseRewriteArgs(append(base, "--name-template", "{org}-{repo}", "--name-map", "names.json"))
+	if err != nil || config.NameTemplate != "{org}-{repo}" || config.NameMap != "names.json" {
+		t.Errorf("--name-template と --name-map が正しく解析されていません: %+v, %v", config, err)
+	}
+}
+
+// TestParseIdentitiesArgs はidentitiesコマンドの引数解析をテストする
+func TestParseIdentitiesArgs(t *testing.T) {
+	config, err := ParseIdentitiesArgs([]string{})
+	if err != nil {
+		t.Fatalf("引数なしでエラーが発生しました: %v", err)
+	}
+	if config.TargetDir != "." || config.Format != "table" || config.Output != "" {
+		t.Errorf("デフォルト値が正しくありません: %+v", config)
+	}
+
+	config, err = ParseIdentitiesArgs([]string{"-d", "/tmp/src", "--format", "json", "--output", "out.json"})
+	if err != nil {
+		t.Fatalf("引数の解析でエラーが発生しました: %v", err)
+	}
+	if config.TargetDir != "/tmp/src" || config.Format != "json" || config.Output != "out.json" {
+		t.Errorf("引数が正しく解析されていません: %+v", config)
+	}
+
+	if _, err := ParseIdentitiesArgs([]string{"--format", "xml"}); err == nil {
+		t.Error("無効な--formatでエラーが期待されました")
+	}
+}
+
 // TestGetConfigValue はgetConfigValue関数をテストする
 func TestGetConfigValue(t *testing.T) {
 	// 環境変数をクリーンアップ
@@ -313,6 +766,9 @@ func clearTestEnvs() {
 	os.Unsetenv("GITHUB_ORGANIZATION")
 	os.Unsetenv("GITHUB_COLLABORATORS")
 	os.Unsetenv("GIT_REWRITE_DEBUG")
+	os.Unsetenv("GIT_REWRITE_ANONYMIZE_SALT")
+	os.Unsetenv("GITHUB_HOST")
+	os.Unsetenv("GITHUB_API_URL")
 }
 
 // restoreEnv は環境変数を復元する
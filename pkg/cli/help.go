@@ -11,6 +11,7 @@ func ShowHelp() {
 	fmt.Println("利用可能なコマンド:")
 	fmt.Println("  rewrite <github_token> --user <user> --email <email> [options] - Git履歴の書き換えとリモートリポジトリ管理")
 	fmt.Println("  demo <github_token> --user <user> --email <email>              - リモートリポジトリ作成機能のデモ")
+	fmt.Println("  identities [--target-dir <dir>] [--format table|csv|json]      - 全リポジトリのauthor・committer・taggerを一覧表示")
 	fmt.Println("  test                                                           - テストの実行")
 	fmt.Println("  help, --help, -h                                               - このヘルプを表示")
 	fmt.Println("")
@@ -23,9 +24,44 @@ func ShowHelp() {
 	fmt.Println("  --collaborators <list>          コラボレーター設定（例: user1:push,user2:admin）")
 	fmt.Println("  --collaborator-config, -c <file> コラボレーター設定ファイル")
 	fmt.Println("  --push-all                      全ブランチ・タグをプッシュ")
+	fmt.Println("  --remote <name>                 URLの変更・プッシュに使用するリモート（デフォルト: origin）")
+	fmt.Println("  --all-remotes                   GitHubを指す全リモートのURLを変更")
+	fmt.Println("  --name-template <template>      書き換え先のリポジトリ名のテンプレート（例: '{org}-{repo}'）")
+	fmt.Println("  --name-map <file>               元のリポジトリ名と新しいリポジトリ名の対応表ファイル")
+	fmt.Println("  --create-remote                 リモートがないリポジトリにディレクトリ名からリモートを追加")
+	fmt.Println("  --keep-old-remote               移行前のURLをリモート（デフォルト: old-{remote}）として残す")
+	fmt.Println("  --old-remote-name <name>        移行前のURLを残すリモート名")
+	fmt.Println("  --on-collision <strategy>       書き換え先が衝突した場合の処理（error, skip, suffix、デフォルト: error）")
+	fmt.Println("  --github-host <host>            GitHub Enterprise Serverのホスト（例: ghe.corp）")
+	fmt.Println("  --api-url <url>                 GitHub APIのベースURL（デフォルト: https://<host>/api/v3）")
+	fmt.Println("  --source-host <hosts>           書き換え対象とするリモートのホスト（カンマ区切り、デフォルト: github.com）")
+	fmt.Println("  --provider <provider>           書き換え先のホスティングサービス: github, gitlab（デフォルト: github）")
+	fmt.Println("  --host <host>                   --github-host の別名。GitLabのホストの指定にも使用（例: gitlab.example.com）")
 	fmt.Println("  --debug                         デバッグモード")
 	fmt.Println("  --public                        パブリックリポジトリとして作成（デフォルト: プライベート）")
 	fmt.Println("  --enable-actions                GitHub Actions制御を無効化（デフォルトでActions制御は有効）")
+	fmt.Println("  --dirty-policy <policy>         未コミットの変更がある場合の処理（refuse, stash, continue）")
+	fmt.Println("  --prune                         書き換え後にreflog・バックアップ参照を削除してgcを実行")
+	fmt.Println("  --backup-dir <directory>        --prune時のバックアップbundle保存先（--pruneと同時に指定）")
+	fmt.Println("  --since <date>                  この日時以降のコミットのみ書き換える")
+	fmt.Println("  --until <date>                  この日時より前のコミットのみ書き換える")
+	fmt.Println("  --only-branches <list>          指定ブランチから到達可能なコミットのみ書き換える")
+	fmt.Println("  --commit-range <A..B>           指定範囲のコミットのみ書き換える")
+	fmt.Println("  --identity-rules <file>         ディレクトリごとの書き換え先identityルールファイル")
+	fmt.Println("  --owner-rules <file>            書き換え先の所有者の振り分けルールファイル")
+	fmt.Println("  --squash-history                履歴を1つのルートコミットに畳み込む")
+	fmt.Println("  --truncate-before <date>        指定日時より前の履歴を1つのルートコミットに畳み込む")
+	fmt.Println("  --anonymize                     全contributorを決定的な仮名に置き換える")
+	fmt.Println("  --anonymize-salt <salt>         仮名生成に使用するソルト")
+	fmt.Println("  --anonymize-map <file>          仮名の対応表の書き出し先")
+	fmt.Println("  --noreply-email                 GitHubのnoreplyメールアドレスで書き換える（--email不要）")
+	fmt.Println("  --email-check <policy>          メールアドレスがアカウントに登録済みでない場合の処理（error, warn, off）")
+	fmt.Println("  --normalize-eol                 履歴中のCRLFを.gitattributesに従ってLFに正規化")
+	fmt.Println("  --strip-exec-bits               履歴中の不要な実行権限を外す")
+	fmt.Println("  --strip-trailing-whitespace <globs> 履歴中の一致するファイルの行末の空白を削除（例: '*.go,*.md'）")
+	fmt.Println("  --apply-gitignore               現在の.gitignoreに一致するファイルを履歴全体から削除")
+	fmt.Println("  --ref-rules <file>              ブランチ・タグの名前変更ルールファイル")
+	fmt.Println("  --lfs-migrate <glob|size>       一致するファイルを履歴全体でGit LFSに移行（例: 100MB, '*.psd'）")
 	fmt.Println("")
 	fmt.Println("例:")
 	fmt.Println("  git-rewrite --help")
@@ -38,14 +74,35 @@ func ShowHelp() {
 	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --collaborator-config collaborators.json --push-all")
 	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --public --debug")
 	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --enable-actions")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --dirty-policy stash")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --prune --backup-dir ~/backups")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --until 2024-04-01")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --target-dir ~/src --identity-rules identities.json")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --target-dir ~/src --owner-rules owners.json")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --public --squash-history")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --anonymize --anonymize-salt secret --anonymize-map ~/private/map.json")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --noreply-email")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --email-check warn")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --normalize-eol --strip-exec-bits")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --apply-gitignore --prune")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --ref-rules refs.json --push-all")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --lfs-migrate 100MB --push-all")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --remote upstream --all-remotes")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --organization neworg --name-template '{org}-{repo}' --name-map names.json")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --organization neworg --create-remote -d ~/prototypes")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@email.com --organization neworg --keep-old-remote")
+	fmt.Println("  git-rewrite rewrite ghp_xxx --user myuser --email my@corp.com --github-host ghe.corp --api-url https://ghe.corp/api/v3")
+	fmt.Println("  git-rewrite rewrite glpat-xxx --user myuser --email my@corp.com --provider gitlab --host gitlab.example.com --organization platform/backend")
 	fmt.Println("  git-rewrite demo ghp_xxx --user myuser --email my@email.com")
+	fmt.Println("  git-rewrite identities --target-dir ~/src --format csv --output identities.csv")
 	fmt.Println("")
 	fmt.Println("GitHub Actions制御について:")
 	fmt.Println("  デフォルトでは、プッシュ前にGitHub Actionsを無効化し、プッシュ後に有効化します。")
 	fmt.Println("  これにより、プッシュ時にActionsが実行されることを防げます。")
 	fmt.Println("  --enable-actionsオプションを使用すると、この制御を無効化できます。")
+	fmt.Println("  --provider gitlab の場合は、同様にGitLab CI/CDパイプラインを一時停止します。")
 	fmt.Println("後方互換性:")
 	fmt.Println("  環境変数も引き続きサポートされますが、コマンド引数が優先されます。")
 	fmt.Println("  GITHUB_USER, GITHUB_EMAIL, GITHUB_ORGANIZATION, GITHUB_REPOSITORY_OWNER,")
-	fmt.Println("  GITHUB_COLLABORATORS, GIT_REWRITE_DEBUG")
+	fmt.Println("  GITHUB_COLLABORATORS, GIT_REWRITE_DEBUG, GIT_REWRITE_ANONYMIZE_SALT")
 }
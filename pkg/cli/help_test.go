@@ -35,6 +35,7 @@ func TestShowHelp(t *testing.T) {
 		"利用可能なコマンド:",
 		"rewrite",
 		"demo",
+		"identities",
 		"test",
 		"help, --help, -h",
 		"rewriteコマンドのオプション:",
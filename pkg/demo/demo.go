@@ -62,7 +62,7 @@ func RunDemo(githubToken string) error {
 
 	// デモ用のリモートURLを設定（存在しないリポジトリ）
 	demoRepoName := fmt.Sprintf("demo-repo-%s", filepath.Base(tempDir))
-	remoteURL := fmt.Sprintf("https://github.com/%s/%s.git", githubUser, demoRepoName)
+	remoteURL := fmt.Sprintf("https://%s/%s/%s.git", utils.DefaultGitHubHost, githubUser, demoRepoName)
 
 	fmt.Printf("リモートURL設定: %s\n", remoteURL)
 	if _, _, err := utils.RunCommand(tempDir, "git", "remote", "add", "origin", remoteURL); err != nil {
@@ -114,7 +114,7 @@ func RunDemo(githubToken string) error {
 	}
 
 	fmt.Printf("\n=== デモ完了 ===\n")
-	fmt.Printf("作成されたリポジトリ（存在する場合）: https://github.com/%s/%s\n", owner, repoName)
+	fmt.Printf("作成されたリポジトリ（存在する場合）: https://%s/%s/%s\n", utils.DefaultGitHubHost, owner, repoName)
 
 	return nil
 }
@@ -138,7 +138,7 @@ func RunEmptyRepoDemo(githubToken, githubUser, githubEmail string) error {
 	}
 
 	emptyRepoName := fmt.Sprintf("empty-demo-repo-%s", filepath.Base(tempDir))
-	emptyRemoteURL := fmt.Sprintf("https://github.com/%s/%s.git", githubUser, emptyRepoName)
+	emptyRemoteURL := fmt.Sprintf("https://%s/%s/%s.git", utils.DefaultGitHubHost, githubUser, emptyRepoName)
 
 	fmt.Printf("空のリポジトリのリモートURL設定: %s\n", emptyRemoteURL)
 	if _, _, err := utils.RunCommand(tempDir, "git", "remote", "add", "origin", emptyRemoteURL); err != nil {
@@ -0,0 +1,408 @@
+package git
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"git-rewrite/pkg/utils"
+)
+
+// 仮名のドメインと接頭辞
+const (
+	pseudonymPrefix = "contributor-"
+	pseudonymDomain = "example.invalid"
+	pseudonymLength = 8 // 仮名に使用するハッシュの桁数
+
+	anonymizeMapFile = "anonymization-map.json" // 対応表のデフォルトのファイル名
+
+	trailerMapPattern = "git-rewrite-trailers-" // トレーラーの置き換え表の一時ファイル名
+)
+
+// identityTrailerKeys はメッセージ中でidentityを記録するトレーラーのキー
+var identityTrailerKeys = []string{
+	"Signed-off-by", "Co-authored-by", "Reviewed-by", "Acked-by",
+	"Tested-by", "Reported-by", "Suggested-by", "Helped-by",
+}
+
+// identityTrailerPattern はidentityを記録するトレーラー行（例: Signed-off-by: Name <email>）に一致する
+var identityTrailerPattern = regexp.MustCompile(`(?im)^(` + strings.Join(identityTrailerKeys, "|") + `):([ \t]*)(.*?)[ \t]*$`)
+
+// identityValuePattern はトレーラーの値（Name <email>）に一致する
+var identityValuePattern = regexp.MustCompile(`^(.*?)[ \t]*<([^<>]*)>$`)
+
+// taggerPattern はタグオブジェクトのtagger行に一致する
+var taggerPattern = regexp.MustCompile(`(?m)^tagger (.*?) ?<([^<>]*)>(.*)$`)
+
+// PseudonymEntry は元のidentityと仮名の対応を表す
+type PseudonymEntry struct {
+	Names          []string `json:"names"`           // 元の名前（同じメールアドレスで複数ある場合はすべて）
+	Email          string   `json:"email"`           // 元のメールアドレス
+	Pseudonym      string   `json:"pseudonym"`       // 仮名
+	PseudonymEmail string   `json:"pseudonym_email"` // 仮名のメールアドレス
+}
+
+// Anonymizer はcontributorのidentityを決定的な仮名に置き換える
+// 仮名はソルト付きハッシュから生成するため、同じソルトを使えばリポジトリをまたいで同じ人物は同じ仮名になる
+type Anonymizer struct {
+	Salt    string
+	MapPath string
+
+	entries map[string]*PseudonymEntry // 正規化したメールアドレス → 対応
+}
+
+// NewAnonymizer は新しいAnonymizerを作成する
+// 対応表ファイルが既に存在する場合は読み込んで追記する
+func NewAnonymizer(salt, mapPath string) (*Anonymizer, error) {
+	if salt == "" {
+		return nil, fmt.Errorf("匿名化にはソルトが必要です（--anonymize-salt またはGIT_REWRITE_ANONYMIZE_SALT環境変数）")
+	}
+	if mapPath == "" {
+		return nil, fmt.Errorf("匿名化の対応表ファイルのパスが必要です")
+	}
+
+	anonymizer := &Anonymizer{
+		Salt:    salt,
+		MapPath: mapPath,
+		entries: make(map[string]*PseudonymEntry),
+	}
+
+	data, err := os.ReadFile(mapPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return anonymizer, nil
+		}
+		return nil, fmt.Errorf("対応表ファイル読み込みエラー: %v", err)
+	}
+
+	var entries []*PseudonymEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("対応表ファイル解析エラー: %v", err)
+	}
+	for _, entry := range entries {
+		anonymizer.entries[normalizeIdentityKey(entry.Names, entry.Email)] = entry
+	}
+	return anonymizer, nil
+}
+
+// DefaultAnonymizeMapPath は対応表のデフォルトの書き出し先を返す
+// 書き換え対象のリポジトリにコミットされないよう、ユーザーの設定ディレクトリ配下に置く
+func DefaultAnonymizeMapPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("対応表の保存先を決定できません（--anonymize-map を指定してください）: %v", err)
+	}
+	return filepath.Join(configDir, "git-rewrite", anonymizeMapFile), nil
+}
+
+// CheckMapOutsideRepos は対応表ファイルが書き換え対象のリポジトリの外にあることを確認する
+// リポジトリ内に置くと、初回コミットの作成時などに元のidentityを含む対応表がコミット・プッシュされるおそれがある
+func CheckMapOutsideRepos(mapPath string, repoDirs []string) error {
+	absMapPath, err := resolvePath(mapPath)
+	if err != nil {
+		return fmt.Errorf("対応表ファイルのパス解決に失敗しました: %v", err)
+	}
+	for _, repoDir := range repoDirs {
+		absRepoDir, err := resolvePath(repoDir)
+		if err != nil {
+			return fmt.Errorf("リポジトリのパス解決に失敗しました: %v", err)
+		}
+		rel, err := filepath.Rel(absRepoDir, absMapPath)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("対応表ファイルが書き換え対象のリポジトリ内にあります。リポジトリの外のパスを --anonymize-map に指定してください: %s", absMapPath)
+		}
+	}
+	return nil
+}
+
+// resolvePath はシンボリックリンクを解決した絶対パスを返す（存在しない末尾の要素はそのまま残す）
+func resolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir, base := absPath, ""
+	for {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			return filepath.Join(resolved, base), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return absPath, nil
+		}
+		base = filepath.Join(filepath.Base(dir), base)
+		dir = parent
+	}
+}
+
+// Pseudonym は元の名前とメールアドレスに対応する仮名を返す
+func (a *Anonymizer) Pseudonym(name, email string) (string, string) {
+	key := normalizeIdentityKey([]string{name}, email)
+
+	entry, exists := a.entries[key]
+	if !exists {
+		mac := hmac.New(sha256.New, []byte(a.Salt))
+		mac.Write([]byte(key))
+		id := pseudonymPrefix + hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+		entry = &PseudonymEntry{
+			Email:          email,
+			Pseudonym:      id,
+			PseudonymEmail: fmt.Sprintf("%s@%s", id, pseudonymDomain),
+		}
+		a.entries[key] = entry
+	}
+
+	if name != "" && !containsString(entry.Names, name) {
+		entry.Names = append(entry.Names, name)
+	}
+	return entry.Pseudonym, entry.PseudonymEmail
+}
+
+// Save は対応表を所有者のみ読み書き可能なファイルに書き出す
+func (a *Anonymizer) Save() error {
+	entries := make([]*PseudonymEntry, 0, len(a.entries))
+	for _, entry := range a.entries {
+		sort.Strings(entry.Names)
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Pseudonym < entries[j].Pseudonym
+	})
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(a.MapPath), 0700); err != nil {
+		return fmt.Errorf("対応表の保存先ディレクトリ作成エラー: %v", err)
+	}
+	if err := os.WriteFile(a.MapPath, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("対応表ファイル書き込みエラー: %v", err)
+	}
+	// 既存ファイルの権限も制限する
+	return os.Chmod(a.MapPath, 0600)
+}
+
+// buildFilter はリポジトリ内の全identityを仮名に置き換えるシェルスクリプトを生成する
+func (a *Anonymizer) buildFilter(gitDir string) (string, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "log", "--branches", "--tags", "--format=%an%x00%ae%x00%cn%x00%ce")
+	if err != nil {
+		return "", fmt.Errorf("identity一覧取得エラー: %v\nstderr: %s", err, stderr)
+	}
+
+	// (名前, メールアドレス) の組ごとに仮名を割り当てる
+	type identity struct{ name, email string }
+	seen := make(map[identity]bool)
+	var identities []identity
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			continue
+		}
+		for _, id := range []identity{{fields[0], fields[1]}, {fields[2], fields[3]}} {
+			if !seen[id] {
+				seen[id] = true
+				identities = append(identities, id)
+			}
+		}
+	}
+
+	var script strings.Builder
+	script.WriteString("anonymize_identity() {\n")
+	script.WriteString("    case \"$1|$2\" in\n")
+	for _, id := range identities {
+		pseudonym, pseudonymEmail := a.Pseudonym(id.name, id.email)
+		fmt.Fprintf(&script, "    %s) anon_name=%s; anon_email=%s ;;\n",
+			shellQuote(id.name+"|"+id.email), shellQuote(pseudonym), shellQuote(pseudonymEmail))
+	}
+	script.WriteString("    *) anon_name=\"$1\"; anon_email=\"$2\" ;;\n")
+	script.WriteString("    esac\n")
+	script.WriteString("}\n")
+	script.WriteString("if [ \"$rewrite_identity\" = 1 ]; then\n")
+	script.WriteString("    anonymize_identity \"$GIT_AUTHOR_NAME\" \"$GIT_AUTHOR_EMAIL\"\n")
+	script.WriteString("    export GIT_AUTHOR_NAME=\"$anon_name\"\n")
+	script.WriteString("    export GIT_AUTHOR_EMAIL=\"$anon_email\"\n")
+	script.WriteString("    anonymize_identity \"$GIT_COMMITTER_NAME\" \"$GIT_COMMITTER_EMAIL\"\n")
+	script.WriteString("    export GIT_COMMITTER_NAME=\"$anon_name\"\n")
+	script.WriteString("    export GIT_COMMITTER_EMAIL=\"$anon_email\"\n")
+	script.WriteString("fi\n")
+
+	fmt.Printf("%d 件のidentityを仮名に置き換えます。\n", len(identities))
+	return script.String(), nil
+}
+
+// anonymizeTrailers はメッセージ中のidentityを記録するトレーラーの値を仮名に置き換える
+// 置き換えた元の値と仮名の対応を返す
+func (a *Anonymizer) anonymizeTrailers(message string) (string, map[string]string) {
+	replacements := make(map[string]string)
+	anonymized := identityTrailerPattern.ReplaceAllStringFunc(message, func(line string) string {
+		parts := identityTrailerPattern.FindStringSubmatch(line)
+		identity := identityValuePattern.FindStringSubmatch(parts[3])
+		if identity == nil {
+			return line
+		}
+		pseudonym, pseudonymEmail := a.Pseudonym(strings.TrimSpace(identity[1]), identity[2])
+		replacements[parts[3]] = fmt.Sprintf("%s <%s>", pseudonym, pseudonymEmail)
+		return parts[1] + ":" + parts[2] + replacements[parts[3]]
+	})
+	return anonymized, replacements
+}
+
+// trailerAnonymization はコミットメッセージのトレーラーを仮名に置き換えるための状態を表す
+type trailerAnonymization struct {
+	mapPath string // トレーラーの元の値と仮名の対応（タブ区切り）
+}
+
+// prepareTrailerAnonymization はコミットメッセージ中のidentityを記録するトレーラーを検出する
+// 対象のトレーラーがない場合はnilを返す。perlがない場合は置き換えずに検出したトレーラーを表示する
+func (a *Anonymizer) prepareTrailerAnonymization(gitDir string) (*trailerAnonymization, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "log", "--branches", "--tags", "--format=%B")
+	if err != nil {
+		return nil, fmt.Errorf("コミットメッセージ取得エラー: %v\nstderr: %s", err, stderr)
+	}
+	_, replacements := a.anonymizeTrailers(stdout)
+	if len(replacements) == 0 {
+		return nil, nil
+	}
+
+	if _, err := exec.LookPath("perl"); err != nil {
+		fmt.Printf("⚠️  perlが見つからないため、コミットメッセージの %d 件のidentity（Signed-off-byなど）を置き換えません:\n", len(replacements))
+		for _, original := range sortedKeys(replacements) {
+			fmt.Printf("  - %s\n", original)
+		}
+		return nil, nil
+	}
+
+	file, err := os.CreateTemp("", trailerMapPattern)
+	if err != nil {
+		return nil, fmt.Errorf("一時ファイル作成エラー: %v", err)
+	}
+	defer file.Close()
+	for _, original := range sortedKeys(replacements) {
+		fmt.Fprintf(file, "%s\t%s\n", original, replacements[original])
+	}
+
+	fmt.Printf("コミットメッセージの %d 件のidentity（Signed-off-byなど）を仮名に置き換えます。\n", len(replacements))
+	return &trailerAnonymization{mapPath: file.Name()}, nil
+}
+
+// msgFilter はトレーラーの値を対応表に従って置き換えるmsg-filterを生成する
+func (t *trailerAnonymization) msgFilter() string {
+	return fmt.Sprintf(`perl -e 'open(M, "<", $ARGV[0]) or die; while (<M>) { chomp; my ($k, $v) = split(/\t/, $_, 2); $m{$k} = $v } `+
+		`while (<STDIN>) { s/^(%s):([ \t]*)(.*?)[ \t]*$/exists $m{$3} ? "$1:$2$m{$3}" : $&/ie; print }' %s`,
+		strings.Join(identityTrailerKeys, "|"), shellQuote(t.mapPath))
+}
+
+// cleanup は一時ファイルを削除する
+func (t *trailerAnonymization) cleanup() {
+	if t == nil {
+		return
+	}
+	os.Remove(t.mapPath)
+}
+
+// anonymizeTaggers は注釈付きタグのtaggerとメッセージ中のトレーラーを仮名に置き換える
+// filter-branchはタグオブジェクトのtaggerを書き換えないため、書き換え後にタグオブジェクトを作り直す
+func (a *Anonymizer) anonymizeTaggers(gitDir string) (int, error) {
+	tags, err := listRefs(gitDir, tagRefPrefix)
+	if err != nil {
+		return 0, err
+	}
+
+	var updates []refUpdate
+	for _, ref := range tags {
+		stdout, stderr, err := utils.RunCommand(gitDir, "git", "rev-parse", "--verify", ref)
+		if err != nil {
+			return 0, fmt.Errorf("参照取得エラー: %s: %v\nstderr: %s", ref, err, stderr)
+		}
+		object := strings.TrimSpace(stdout)
+		if objectType, _, err := utils.RunCommand(gitDir, "git", "cat-file", "-t", object); err != nil || strings.TrimSpace(objectType) != "tag" {
+			// 軽量タグ
+			continue
+		}
+
+		content, stderr, err := utils.RunCommand(gitDir, "git", "cat-file", "tag", object)
+		if err != nil {
+			return 0, fmt.Errorf("タグ取得エラー: %s: %v\nstderr: %s", ref, err, stderr)
+		}
+		header, message, _ := strings.Cut(content, "\n\n")
+		header = taggerPattern.ReplaceAllStringFunc(header, func(line string) string {
+			parts := taggerPattern.FindStringSubmatch(line)
+			pseudonym, pseudonymEmail := a.Pseudonym(strings.TrimSpace(parts[1]), parts[2])
+			return fmt.Sprintf("tagger %s <%s>%s", pseudonym, pseudonymEmail, parts[3])
+		})
+		message, _ = a.anonymizeTrailers(message)
+		// taggerが変わると署名は無効になるため取り除く
+		anonymized := stripTagSignature(header + "\n\n" + message)
+		if anonymized == content {
+			continue
+		}
+
+		cmd := exec.Command("git", "mktag")
+		cmd.Dir = gitDir
+		cmd.Stdin = strings.NewReader(anonymized)
+		var output, errOutput bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &errOutput
+		if err := cmd.Run(); err != nil {
+			return 0, fmt.Errorf("タグ作成エラー: %s: %v\nstderr: %s", ref, err, utils.SafeDecode(errOutput.Bytes()))
+		}
+
+		name := strings.TrimPrefix(ref, tagRefPrefix)
+		updates = append(updates, refUpdate{
+			RefChange: RefChange{Type: RefTypeTag, Old: name, New: name},
+			current:   object,
+			object:    strings.TrimSpace(output.String()),
+		})
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	if err := applyRefTransaction(gitDir, updates); err != nil {
+		return 0, err
+	}
+	return len(updates), nil
+}
+
+// sortedKeys はマップのキーを整列して返す
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeIdentityKey は仮名の生成に使用するキーを返す
+// メールアドレスがある場合は大文字小文字を区別しないメールアドレス、ない場合は名前を使用する
+func normalizeIdentityKey(names []string, email string) string {
+	if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+		return email
+	}
+	if len(names) > 0 {
+		return "name:" + strings.TrimSpace(names[0])
+	}
+	return ""
+}
+
+// containsString はスライスに文字列が含まれているかを返す
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
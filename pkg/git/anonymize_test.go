@@ -0,0 +1,172 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// TestAnonymizerPseudonym は仮名の決定性をテストする
+func TestAnonymizerPseudonym(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := NewAnonymizer("salt", filepath.Join(dir, "first.json"))
+	if err != nil {
+		t.Fatalf("NewAnonymizerでエラーが発生しました: %v", err)
+	}
+	second, _ := NewAnonymizer("salt", filepath.Join(dir, "second.json"))
+	otherSalt, _ := NewAnonymizer("other", filepath.Join(dir, "other.json"))
+
+	name, email := first.Pseudonym("Alice", "alice@corp.example")
+	if !strings.HasPrefix(name, "contributor-") || email != name+"@example.invalid" {
+		t.Errorf("仮名の形式が正しくありません: %s <%s>", name, email)
+	}
+
+	// 同じソルトなら別のAnonymizerでも同じ仮名になる（メールアドレスの大文字小文字は区別しない）
+	if again, _ := second.Pseudonym("Alice Smith", "Alice@Corp.example"); again != name {
+		t.Errorf("同じ人物に異なる仮名が割り当てられました: %s, %s", name, again)
+	}
+	if other, _ := otherSalt.Pseudonym("Alice", "alice@corp.example"); other == name {
+		t.Error("異なるソルトで同じ仮名が生成されました")
+	}
+	if bob, _ := first.Pseudonym("Bob", "bob@corp.example"); bob == name {
+		t.Error("異なる人物に同じ仮名が割り当てられました")
+	}
+
+	if _, err := NewAnonymizer("", filepath.Join(dir, "nosalt.json")); err == nil {
+		t.Error("ソルトなしでエラーが期待されました")
+	}
+}
+
+// TestAnonymizerSave は対応表の保存と再読み込みをテストする
+func TestAnonymizerSave(t *testing.T) {
+	// 保存先のディレクトリが存在しない場合は作成する
+	mapPath := filepath.Join(t.TempDir(), "git-rewrite", "map.json")
+
+	anonymizer, _ := NewAnonymizer("salt", mapPath)
+	name, _ := anonymizer.Pseudonym("Alice", "alice@corp.example")
+	anonymizer.Pseudonym("Alice (laptop)", "alice@corp.example")
+	if err := anonymizer.Save(); err != nil {
+		t.Fatalf("Saveでエラーが発生しました: %v", err)
+	}
+
+	info, err := os.Stat(mapPath)
+	if err != nil {
+		t.Fatalf("対応表ファイルが作成されていません: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("対応表ファイルの権限が0600ではありません: %v", info.Mode().Perm())
+	}
+
+	reloaded, err := NewAnonymizer("salt", mapPath)
+	if err != nil {
+		t.Fatalf("対応表の再読み込みでエラーが発生しました: %v", err)
+	}
+	entry := reloaded.entries["alice@corp.example"]
+	if entry == nil || entry.Pseudonym != name || len(entry.Names) != 2 {
+		t.Errorf("対応表が正しく保存されていません: %+v", entry)
+	}
+}
+
+// TestCheckMapOutsideRepos はリポジトリ内の対応表ファイルが拒否されることをテストする
+func TestCheckMapOutsideRepos(t *testing.T) {
+	root := t.TempDir()
+	repos := []string{filepath.Join(root, "app"), filepath.Join(root, "lib")}
+
+	tests := []struct {
+		mapPath string
+		wantErr bool
+	}{
+		{filepath.Join(root, "map.json"), false},
+		{filepath.Join(root, "application", "map.json"), false},
+		{filepath.Join(root, "app", "map.json"), true},
+		{filepath.Join(root, "lib", "docs", "map.json"), true},
+	}
+	for _, tt := range tests {
+		err := CheckMapOutsideRepos(tt.mapPath, repos)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckMapOutsideRepos(%s): エラー期待値 %t, 実際 %v", tt.mapPath, tt.wantErr, err)
+		}
+	}
+}
+
+// TestRewriteHistoryWithAnonymizer は匿名化した書き換えをテストする
+func TestRewriteHistoryWithAnonymizer(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commitWithDate(t, repo, "alice-commit", "2022-01-01T00:00:00Z", "alice@corp.example")
+	commitWithDate(t, repo, "bob-commit", "2022-02-01T00:00:00Z", "bob@corp.example")
+
+	mapPath := filepath.Join(t.TempDir(), "map.json")
+	anonymizer, _ := NewAnonymizer("salt", mapPath)
+	opts := RewriteOptions{Anonymizer: anonymizer}
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", opts); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	emails := authorEmails(t, repo, "HEAD")
+	_, aliceEmail := anonymizer.Pseudonym("", "alice@corp.example")
+	_, bobEmail := anonymizer.Pseudonym("", "bob@corp.example")
+	if emails["alice-commit"] != aliceEmail || emails["bob-commit"] != bobEmail {
+		t.Errorf("仮名に置き換えられていません: %v", emails)
+	}
+	if aliceEmail == bobEmail {
+		t.Error("異なる人物が同じ仮名になりました")
+	}
+
+	stdout, _, _ := utils.RunCommand(repo, "git", "log", "--format=%an %ae %cn %ce", "HEAD")
+	if strings.Contains(stdout, "corp.example") || strings.Contains(stdout, "Test User") {
+		t.Errorf("元のidentityが残っています: %s", stdout)
+	}
+	if !utils.FileExists(mapPath) {
+		t.Error("対応表ファイルが作成されていません")
+	}
+}
+
+// TestRewriteHistoryAnonymizesTaggersAndTrailers は注釈付きタグのtaggerとメッセージのトレーラーの匿名化をテストする
+func TestRewriteHistoryAnonymizesTaggersAndTrailers(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commands := [][]string{
+		{"git", "commit", "--allow-empty", "-m", "pair work\n\nSigned-off-by: Alice Real <alice@corp.example>\nco-authored-by: Bob Real <bob@corp.example>"},
+		{"git", "-c", "user.name=Carol Real", "-c", "user.email=carol@corp.example", "tag", "-a", "v1.0",
+			"-m", "release\n\nReviewed-by: Dave Real <dave@corp.example>"},
+		{"git", "tag", "v1.0-light"},
+	}
+	for _, args := range commands {
+		if _, stderr, err := utils.RunCommand(repo, args[0], args[1:]...); err != nil {
+			t.Fatalf("%v エラー: %v, stderr: %s", args, err, stderr)
+		}
+	}
+
+	anonymizer, _ := NewAnonymizer("salt", filepath.Join(t.TempDir(), "map.json"))
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", RewriteOptions{Anonymizer: anonymizer}); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	message, _, _ := utils.RunCommand(repo, "git", "log", "-1", "--format=%B", "HEAD")
+	tag, _, _ := utils.RunCommand(repo, "git", "cat-file", "tag", "v1.0")
+	for _, output := range []string{message, tag} {
+		if strings.Contains(output, "Real") || strings.Contains(output, "corp.example") {
+			t.Errorf("元のidentityが残っています:\n%s", output)
+		}
+	}
+
+	aliceName, aliceEmail := anonymizer.Pseudonym("", "alice@corp.example")
+	bobName, bobEmail := anonymizer.Pseudonym("", "bob@corp.example")
+	if !strings.Contains(message, "Signed-off-by: "+aliceName+" <"+aliceEmail+">") ||
+		!strings.Contains(message, "co-authored-by: "+bobName+" <"+bobEmail+">") {
+		t.Errorf("トレーラーが仮名に置き換えられていません:\n%s", message)
+	}
+	carolName, carolEmail := anonymizer.Pseudonym("", "carol@corp.example")
+	if !strings.Contains(tag, "tagger "+carolName+" <"+carolEmail+">") {
+		t.Errorf("taggerが仮名に置き換えられていません:\n%s", tag)
+	}
+
+	// タグは書き換え後のコミットを指したまま
+	tagged, _, _ := utils.RunCommand(repo, "git", "rev-parse", "v1.0^{commit}", "v1.0-light", "HEAD")
+	if lines := strings.Fields(tagged); len(lines) != 3 || lines[0] != lines[2] || lines[1] != lines[2] {
+		t.Errorf("タグが書き換え後のコミットを指していません: %v", lines)
+	}
+}
@@ -0,0 +1,155 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"git-rewrite/pkg/utils"
+)
+
+// CommitCondition は書き換え対象とするコミットの条件を表す
+// 条件が複数指定された場合は、すべてを満たすコミットのみが書き換え対象になる
+type CommitCondition struct {
+	Since    time.Time // この日時以降のコミットのみ対象（authorの日時で判定）
+	Until    time.Time // この日時より前のコミットのみ対象（authorの日時で判定）
+	Branches []string  // これらのブランチから到達可能なコミットのみ対象
+	Range    string    // コミット範囲（例: A..B）に含まれるコミットのみ対象
+}
+
+// IsEmpty は条件が指定されていないかどうかを返す
+func (c *CommitCondition) IsEmpty() bool {
+	return c.Since.IsZero() && c.Until.IsZero() && len(c.Branches) == 0 && c.Range == ""
+}
+
+// String は条件を表示用の文字列に変換する
+func (c *CommitCondition) String() string {
+	var parts []string
+	if !c.Since.IsZero() {
+		parts = append(parts, fmt.Sprintf("%s以降", c.Since.Format("2006-01-02 15:04:05")))
+	}
+	if !c.Until.IsZero() {
+		parts = append(parts, fmt.Sprintf("%sより前", c.Until.Format("2006-01-02 15:04:05")))
+	}
+	if len(c.Branches) > 0 {
+		parts = append(parts, fmt.Sprintf("ブランチ %s から到達可能", strings.Join(c.Branches, ", ")))
+	}
+	if c.Range != "" {
+		parts = append(parts, fmt.Sprintf("範囲 %s", c.Range))
+	}
+	if len(parts) == 0 {
+		return "すべてのコミット"
+	}
+	return strings.Join(parts, "、")
+}
+
+// ParseDate は日付文字列を解析する（YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS, RFC3339）
+// タイムゾーンが指定されていない場合はローカルタイムとして扱う
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("日付の形式が正しくありません: %s (例: 2024-04-01, 2024-04-01T09:00:00+09:00)", value)
+}
+
+// buildConditionFilter は条件を満たさないコミットでrewrite_identity=0とするシェルスクリプトを生成する
+// commitListPathには対象コミットの一覧ファイルを指定する（ブランチ・範囲条件がない場合は空）
+func buildConditionFilter(cond *CommitCondition, commitListPath string) string {
+	var script strings.Builder
+	script.WriteString("rewrite_identity=1\n")
+
+	if !cond.Since.IsZero() || !cond.Until.IsZero() {
+		// filter-branchはGIT_AUTHOR_DATEを "@<unix時刻> <タイムゾーン>" 形式で渡す
+		script.WriteString("author_ts=${GIT_AUTHOR_DATE#@}\n")
+		script.WriteString("author_ts=${author_ts%% *}\n")
+		if !cond.Since.IsZero() {
+			fmt.Fprintf(&script, "if [ \"$author_ts\" -lt %d ]; then rewrite_identity=0; fi\n", cond.Since.Unix())
+		}
+		if !cond.Until.IsZero() {
+			fmt.Fprintf(&script, "if [ \"$author_ts\" -ge %d ]; then rewrite_identity=0; fi\n", cond.Until.Unix())
+		}
+	}
+
+	if commitListPath != "" {
+		fmt.Fprintf(&script, "if ! grep -qx \"$GIT_COMMIT\" %s; then rewrite_identity=0; fi\n", shellQuote(commitListPath))
+	}
+
+	return script.String()
+}
+
+// writeCommitList はブランチ・範囲条件に一致するコミットの一覧を一時ファイルに書き出す
+// 条件がない場合は空文字列を返す。呼び出し側で一時ファイルを削除する必要がある
+func writeCommitList(gitDir string, cond *CommitCondition) (string, error) {
+	if len(cond.Branches) == 0 && cond.Range == "" {
+		return "", nil
+	}
+
+	var commits map[string]bool
+	if len(cond.Branches) > 0 {
+		set, err := revListSet(gitDir, cond.Branches...)
+		if err != nil {
+			return "", err
+		}
+		commits = set
+	}
+	if cond.Range != "" {
+		set, err := revListSet(gitDir, cond.Range)
+		if err != nil {
+			return "", err
+		}
+		if commits == nil {
+			commits = set
+		} else {
+			// ブランチ条件と範囲条件の両方を満たすコミットのみ残す
+			for commit := range commits {
+				if !set[commit] {
+					delete(commits, commit)
+				}
+			}
+		}
+	}
+
+	file, err := os.CreateTemp("", "git-rewrite-commits-")
+	if err != nil {
+		return "", fmt.Errorf("一時ファイル作成エラー: %v", err)
+	}
+	defer file.Close()
+
+	for commit := range commits {
+		if _, err := fmt.Fprintln(file, commit); err != nil {
+			os.Remove(file.Name())
+			return "", fmt.Errorf("一時ファイル書き込みエラー: %v", err)
+		}
+	}
+
+	return file.Name(), nil
+}
+
+// revListSet はgit rev-listの結果をセットとして返す
+func revListSet(gitDir string, revs ...string) (map[string]bool, error) {
+	args := append([]string{"rev-list"}, revs...)
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", args...)
+	if err != nil {
+		return nil, fmt.Errorf("コミット一覧取得エラー (%s): %v\nstderr: %s", strings.Join(revs, " "), err, stderr)
+	}
+
+	set := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if line != "" {
+			set[line] = true
+		}
+	}
+	return set, nil
+}
+
+// shellQuote は文字列をシェルのシングルクォートで囲む
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
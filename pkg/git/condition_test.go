@@ -0,0 +1,147 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"git-rewrite/pkg/utils"
+)
+
+// commitWithDate は指定した日時とauthorでコミットを作成する
+func commitWithDate(t *testing.T, repo, message, date, email string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(repo, "file.txt"), []byte(message+"\n"), 0644); err != nil {
+		t.Fatalf("ファイル作成エラー: %v", err)
+	}
+	if _, stderr, err := utils.RunCommand(repo, "git", "add", "file.txt"); err != nil {
+		t.Fatalf("git add エラー: %v, stderr: %s", err, stderr)
+	}
+	if _, stderr, err := utils.RunCommand(repo, "git", "-c", "user.email="+email,
+		"commit", "-m", message, "--date", date); err != nil {
+		t.Fatalf("git commit エラー: %v, stderr: %s", err, stderr)
+	}
+}
+
+// authorEmails はコミットメッセージとauthor emailの対応を取得する
+func authorEmails(t *testing.T, repo, rev string) map[string]string {
+	t.Helper()
+
+	stdout, stderr, err := utils.RunCommand(repo, "git", "log", "--format=%s %ae", rev)
+	if err != nil {
+		t.Fatalf("git log エラー: %v, stderr: %s", err, stderr)
+	}
+
+	emails := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) == 2 {
+			emails[parts[0]] = parts[1]
+		}
+	}
+	return emails
+}
+
+// TestParseDate は日付文字列の解析をテストする
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Time
+		shouldError bool
+	}{
+		{"2024-04-01", time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local), false},
+		{"2024-04-01T09:30:00", time.Date(2024, 4, 1, 9, 30, 0, 0, time.Local), false},
+		{"2024-04-01T09:30:00Z", time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC), false},
+		{"2024/04/01", time.Time{}, true},
+		{"", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseDate(tt.value)
+		if tt.shouldError {
+			if err == nil {
+				t.Errorf("ParseDate(%q): エラーが期待されました", tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDate(%q): エラーが発生しました: %v", tt.value, err)
+			continue
+		}
+		if !result.Equal(tt.expected) {
+			t.Errorf("ParseDate(%q): 期待値 %v, 実際 %v", tt.value, tt.expected, result)
+		}
+	}
+}
+
+// TestRewriteHistoryWithDateCondition は日時条件付きの書き換えをテストする
+func TestRewriteHistoryWithDateCondition(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commitWithDate(t, repo, "before", "2020-01-01T00:00:00Z", "old@corp.example")
+	commitWithDate(t, repo, "after", "2022-01-01T00:00:00Z", "new@corp.example")
+
+	cutover, _ := ParseDate("2021-01-01T00:00:00Z")
+	opts := RewriteOptions{Condition: CommitCondition{Until: cutover}}
+	if err := RewriteHistoryWithOptions(repo, "newuser", "rewritten@example.com", opts); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	emails := authorEmails(t, repo, "HEAD")
+	if emails["before"] != "rewritten@example.com" {
+		t.Errorf("切り替え日より前のコミットが書き換えられていません: %s", emails["before"])
+	}
+	if emails["after"] != "new@corp.example" {
+		t.Errorf("切り替え日以降のコミットが書き換えられました: %s", emails["after"])
+	}
+}
+
+// TestRewriteHistoryWithBranchCondition はブランチ・範囲条件付きの書き換えをテストする
+func TestRewriteHistoryWithBranchCondition(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	utils.RunCommand(repo, "git", "branch", "-M", "main")
+	commitWithDate(t, repo, "main-commit", "2022-01-01T00:00:00Z", "main@corp.example")
+	utils.RunCommand(repo, "git", "checkout", "-b", "feature")
+	commitWithDate(t, repo, "feature-commit", "2022-02-01T00:00:00Z", "feature@corp.example")
+	utils.RunCommand(repo, "git", "checkout", "main")
+
+	t.Run("範囲条件", func(t *testing.T) {
+		opts := RewriteOptions{Condition: CommitCondition{Range: "main..feature"}}
+		if err := RewriteHistoryWithOptions(repo, "newuser", "rewritten@example.com", opts); err != nil {
+			t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+		}
+
+		emails := authorEmails(t, repo, "feature")
+		if emails["feature-commit"] != "rewritten@example.com" {
+			t.Errorf("範囲内のコミットが書き換えられていません: %s", emails["feature-commit"])
+		}
+		if emails["main-commit"] != "main@corp.example" {
+			t.Errorf("範囲外のコミットが書き換えられました: %s", emails["main-commit"])
+		}
+	})
+
+	t.Run("存在しないブランチ", func(t *testing.T) {
+		opts := RewriteOptions{Condition: CommitCondition{Branches: []string{"no-such-branch"}}}
+		if err := RewriteHistoryWithOptions(repo, "newuser", "rewritten@example.com", opts); err == nil {
+			t.Error("存在しないブランチでエラーが期待されました")
+		}
+	})
+}
+
+// TestCommitConditionString は条件の表示をテストする
+func TestCommitConditionString(t *testing.T) {
+	empty := CommitCondition{}
+	if !empty.IsEmpty() || empty.String() != "すべてのコミット" {
+		t.Errorf("空の条件が正しく表示されません: %s", empty.String())
+	}
+
+	cond := CommitCondition{Branches: []string{"main"}, Range: "A..B"}
+	if cond.IsEmpty() {
+		t.Error("条件が指定されているのにIsEmptyがtrueです")
+	}
+	if !strings.Contains(cond.String(), "main") || !strings.Contains(cond.String(), "A..B") {
+		t.Errorf("条件の表示が正しくありません: %s", cond.String())
+	}
+}
@@ -0,0 +1,196 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"git-rewrite/pkg/utils"
+)
+
+// 変換用の一時ディレクトリ内のファイル名
+const (
+	encodingDirPattern = "git-rewrite-encoding-"
+	encodingListFile   = "commits" // 変換対象コミットとエンコーディングの一覧
+	encodingFailedFile = "failed"  // 変換に失敗したコミットと項目の一覧
+)
+
+// encodingFields は変換対象の項目（表示順）
+var encodingFields = []string{"author", "committer", "message"}
+
+// EncodingFailure はUTF-8に変換できなかったコミットを表す
+type EncodingFailure struct {
+	Commit   string   // 書き換え前のコミットID
+	Encoding string   // コミットに記録されていたエンコーディング
+	Fields   []string // 変換できなかった項目（author, committer, message）
+}
+
+// encodingConversion はi18n.commitEncodingで記録されたコミットをUTF-8に変換するための状態を表す
+type encodingConversion struct {
+	dir       string            // 一時ディレクトリ
+	encodings map[string]string // コミットID → エンコーディング
+}
+
+// prepareEncodingConversion はUTF-8以外のエンコーディングヘッダーを持つコミットを検出する
+// 対象のコミットがない場合はnilを返す
+func prepareEncodingConversion(gitDir string) (*encodingConversion, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "log", "--branches", "--tags", "--format=%H %e")
+	if err != nil {
+		return nil, fmt.Errorf("コミット一覧取得エラー: %v\nstderr: %s", err, stderr)
+	}
+
+	encodings := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || isUTF8Encoding(fields[1]) {
+			continue
+		}
+		encodings[fields[0]] = fields[1]
+	}
+	if len(encodings) == 0 {
+		return nil, nil
+	}
+
+	if _, err := exec.LookPath("iconv"); err != nil {
+		fmt.Printf("⚠️  %d 個のコミットがUTF-8以外で記録されていますが、iconvが見つからないため変換しません。\n", len(encodings))
+		return nil, nil
+	}
+
+	dir, err := os.MkdirTemp("", encodingDirPattern)
+	if err != nil {
+		return nil, fmt.Errorf("一時ディレクトリ作成エラー: %v", err)
+	}
+	conversion := &encodingConversion{dir: dir, encodings: encodings}
+
+	var list strings.Builder
+	for commit, encoding := range encodings {
+		fmt.Fprintf(&list, "%s %s\n", commit, encoding)
+	}
+	if err := os.WriteFile(conversion.path(encodingListFile), []byte(list.String()), 0600); err != nil {
+		conversion.cleanup()
+		return nil, fmt.Errorf("一時ファイル書き込みエラー: %v", err)
+	}
+
+	fmt.Printf("%d 個のコミットのメッセージと名前をUTF-8に変換します。\n", len(encodings))
+	return conversion, nil
+}
+
+// envFilter はauthor・committerの名前をUTF-8に変換するenv-filterを生成する
+// 匿名化などのidentityの照合より前に実行する必要がある
+// いずれかの項目を変換できないコミットは名前・メッセージとも変換せず、元のエンコーディングヘッダーを付けて記録する
+// （UTF-8として記録するi18n.commitEncodingの指定を、GIT_CONFIG_PARAMETERSでコミットごとに上書きする）
+func (e *encodingConversion) envFilter() string {
+	return fmt.Sprintf(`
+if [ -z "${git_rewrite_config_parameters+set}" ]; then
+    git_rewrite_config_parameters="$GIT_CONFIG_PARAMETERS"
+fi
+export GIT_CONFIG_PARAMETERS="$git_rewrite_config_parameters"
+commit_encoding=$(sed -n "s/^$GIT_COMMIT //p" %[1]s)
+if [ -n "$commit_encoding" ]; then
+    encoding_failed=
+    if ! author_name=$(printf '%%s' "$GIT_AUTHOR_NAME" | iconv -f "$commit_encoding" -t UTF-8 2>/dev/null); then
+        echo "$GIT_COMMIT author" >> %[2]s
+        encoding_failed=1
+    fi
+    if ! committer_name=$(printf '%%s' "$GIT_COMMITTER_NAME" | iconv -f "$commit_encoding" -t UTF-8 2>/dev/null); then
+        echo "$GIT_COMMIT committer" >> %[2]s
+        encoding_failed=1
+    fi
+    if ! git cat-file commit "$GIT_COMMIT" | sed '1,/^$/d' | iconv -f "$commit_encoding" -t UTF-8 >/dev/null 2>&1; then
+        echo "$GIT_COMMIT message" >> %[2]s
+        encoding_failed=1
+    fi
+    if [ -z "$encoding_failed" ]; then
+        export GIT_AUTHOR_NAME="$author_name"
+        export GIT_COMMITTER_NAME="$committer_name"
+    else
+        export GIT_CONFIG_PARAMETERS="$git_rewrite_config_parameters 'i18n.commitencoding=$commit_encoding'"
+    fi
+fi
+`, shellQuote(e.path(encodingListFile)), shellQuote(e.path(encodingFailedFile)))
+}
+
+// msgFilter はコミットメッセージをUTF-8に変換するmsg-filterを生成する
+// env-filterで変換できないと判定したコミットのメッセージはそのまま出力する
+func (e *encodingConversion) msgFilter() string {
+	return fmt.Sprintf(`commit_encoding=$(sed -n "s/^$GIT_COMMIT //p" %[1]s)
+if [ -z "$commit_encoding" ] || grep -q "^$GIT_COMMIT " %[2]s 2>/dev/null; then
+    cat
+else
+    iconv -f "$commit_encoding" -t UTF-8
+fi`, shellQuote(e.path(encodingListFile)), shellQuote(e.path(encodingFailedFile)))
+}
+
+// failures は変換に失敗したコミットの一覧を返す
+func (e *encodingConversion) failures() []EncodingFailure {
+	data, err := os.ReadFile(e.path(encodingFailedFile))
+	if err != nil {
+		return nil
+	}
+
+	failed := make(map[string]map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+		if failed[parts[0]] == nil {
+			failed[parts[0]] = make(map[string]bool)
+		}
+		failed[parts[0]][parts[1]] = true
+	}
+
+	failures := make([]EncodingFailure, 0, len(failed))
+	for commit, fields := range failed {
+		failure := EncodingFailure{Commit: commit, Encoding: e.encodings[commit]}
+		for _, field := range encodingFields {
+			if fields[field] {
+				failure.Fields = append(failure.Fields, field)
+			}
+		}
+		failures = append(failures, failure)
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Commit < failures[j].Commit
+	})
+	return failures
+}
+
+// report は変換結果を表示する
+func (e *encodingConversion) report() {
+	failures := e.failures()
+	if len(failures) == 0 {
+		fmt.Printf("✅ %d 個のコミットをUTF-8に変換しました。\n", len(e.encodings))
+		return
+	}
+
+	fmt.Printf("⚠️  %d 個のコミットをUTF-8に変換できませんでした（元のエンコーディングのまま記録しています）:\n", len(failures))
+	for _, failure := range failures {
+		fmt.Printf("  - %s (%s): %s\n", failure.Commit, failure.Encoding, strings.Join(failure.Fields, ", "))
+	}
+}
+
+// cleanup は一時ディレクトリを削除する
+func (e *encodingConversion) cleanup() {
+	if e == nil {
+		return
+	}
+	os.RemoveAll(e.dir)
+}
+
+// path は一時ディレクトリ内のファイルパスを返す
+func (e *encodingConversion) path(name string) string {
+	return filepath.Join(e.dir, name)
+}
+
+// isUTF8Encoding はエンコーディング名がUTF-8を表すかどうかを判定する
+func isUTF8Encoding(encoding string) bool {
+	switch strings.ToLower(encoding) {
+	case "", "utf-8", "utf8":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// commitWithEncoding は指定したエンコーディングのバイト列でメッセージとauthor名を記録したコミットを作成する
+func commitWithEncoding(t *testing.T, repo, encoding, name string, message []byte) {
+	t.Helper()
+
+	messagePath := filepath.Join(t.TempDir(), "message")
+	if err := os.WriteFile(messagePath, message, 0644); err != nil {
+		t.Fatalf("ファイル作成エラー: %v", err)
+	}
+	if _, stderr, err := utils.RunCommand(repo, "git", "-c", "i18n.commitEncoding="+encoding, "-c", "user.name="+name,
+		"commit", "--allow-empty", "-F", messagePath); err != nil {
+		t.Fatalf("git commit エラー: %v, stderr: %s", err, stderr)
+	}
+}
+
+// rawCommit はコミットオブジェクトの内容をそのまま取得する
+func rawCommit(t *testing.T, repo, rev string) string {
+	t.Helper()
+
+	stdout, stderr, err := utils.RunCommand(repo, "git", "cat-file", "commit", rev)
+	if err != nil {
+		t.Fatalf("git cat-file エラー: %v, stderr: %s", err, stderr)
+	}
+	return stdout
+}
+
+// TestRewriteHistoryConvertsLegacyEncoding はShift_JIS・EUC-JPのコミットがUTF-8に変換されることをテストする
+func TestRewriteHistoryConvertsLegacyEncoding(t *testing.T) {
+	repo := setupPreflightRepo(t)
+
+	// 「テスト」のShift_JIS表現と「日本語」のEUC-JP表現
+	shiftJIS := string([]byte{0x83, 0x65, 0x83, 0x58, 0x83, 0x67})
+	eucJP := string([]byte{0xc6, 0xfc, 0xcb, 0xdc, 0xb8, 0xec})
+	commitWithEncoding(t, repo, "Shift_JIS", shiftJIS, []byte(shiftJIS+" message\n"))
+	commitWithEncoding(t, repo, "EUC-JP", eucJP, []byte(eucJP+" message\n"))
+
+	// 条件付きで一部のコミットのみidentityを書き換え、名前の変換を確認する
+	opts := RewriteOptions{Condition: CommitCondition{Range: "HEAD~2..HEAD~1"}}
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", opts); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	eucCommit := rawCommit(t, repo, "HEAD")
+	if strings.Contains(eucCommit, "\nencoding ") {
+		t.Errorf("エンコーディングヘッダーが残っています:\n%s", eucCommit)
+	}
+	if !strings.Contains(eucCommit, "author 日本語 <") || !strings.Contains(eucCommit, "\n\n日本語 message") {
+		t.Errorf("EUC-JPのコミットがUTF-8に変換されていません:\n%s", eucCommit)
+	}
+
+	sjisCommit := rawCommit(t, repo, "HEAD~1")
+	if strings.Contains(sjisCommit, "\nencoding ") || !strings.Contains(sjisCommit, "\n\nテスト message") {
+		t.Errorf("Shift_JISのコミットがUTF-8に変換されていません:\n%s", sjisCommit)
+	}
+	if !strings.Contains(sjisCommit, "author newuser <new@example.com>") {
+		t.Errorf("対象コミットのidentityが書き換えられていません:\n%s", sjisCommit)
+	}
+}
+
+// TestEncodingConversionFailures は変換できなかったコミットが報告されることをテストする
+func TestEncodingConversionFailures(t *testing.T) {
+	t.Setenv("FILTER_BRANCH_SQUELCH_WARNING", "1")
+	repo := setupPreflightRepo(t)
+
+	// Shift_JISとして不正なバイト列
+	commitWithEncoding(t, repo, "Shift_JIS", "valid", []byte{0xff, 0xfe, '\n'})
+	head, _, _ := utils.RunCommand(repo, "git", "rev-parse", "HEAD")
+
+	conversion, err := prepareEncodingConversion(repo)
+	if err != nil || conversion == nil {
+		t.Fatalf("変換対象のコミットが検出されませんでした: %v", err)
+	}
+	defer conversion.cleanup()
+
+	args := []string{"-c", "i18n.commitEncoding=UTF-8", "filter-branch", "-f",
+		"--env-filter", conversion.envFilter(), "--msg-filter", conversion.msgFilter(), "--", "--branches"}
+	if _, stderr, err := utils.RunCommand(repo, "git", args...); err != nil {
+		t.Fatalf("git filter-branch エラー: %v, stderr: %s", err, stderr)
+	}
+
+	failures := conversion.failures()
+	if len(failures) != 1 {
+		t.Fatalf("変換失敗が1件報告されることが期待されました: %+v", failures)
+	}
+	if failures[0].Commit != strings.TrimSpace(head) || failures[0].Encoding != "Shift_JIS" {
+		t.Errorf("変換失敗のコミットが正しくありません: %+v", failures[0])
+	}
+	if len(failures[0].Fields) != 1 || failures[0].Fields[0] != "message" {
+		t.Errorf("変換失敗の項目が正しくありません: %v", failures[0].Fields)
+	}
+
+	// 変換できなかったコミットはUTF-8としてではなく元のエンコーディングで記録される
+	if commit := rawCommit(t, repo, "HEAD"); !strings.Contains(commit, "\nencoding Shift_JIS\n") {
+		t.Errorf("変換できなかったコミットのエンコーディングヘッダーが保持されていません:\n%s", commit)
+	}
+	// 変換できたコミットは引き続きUTF-8として記録される
+	if commit := rawCommit(t, repo, "HEAD~1"); strings.Contains(commit, "\nencoding ") {
+		t.Errorf("変換対象外のコミットにエンコーディングヘッダーが付与されました:\n%s", commit)
+	}
+}
+
+// TestPrepareEncodingConversionUTF8Only はUTF-8のみのリポジトリでは変換しないことをテストする
+func TestPrepareEncodingConversionUTF8Only(t *testing.T) {
+	repo := setupPreflightRepo(t)
+
+	conversion, err := prepareEncodingConversion(repo)
+	if err != nil {
+		t.Fatalf("prepareEncodingConversionでエラーが発生しました: %v", err)
+	}
+	if conversion != nil {
+		t.Error("UTF-8のみのリポジトリで変換対象が検出されました")
+	}
+}
@@ -0,0 +1,230 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"git-rewrite/pkg/utils"
+)
+
+// ignoreRulesPattern は一時的に書き出す無視ルールファイルの名前
+const ignoreRulesPattern = "git-rewrite-gitignore-"
+
+// ignoreFilter は現在のHEADの.gitignoreを過去の全コミットに適用するための状態を表す
+type ignoreFilter struct {
+	rulesPath    string   // HEADの全.gitignoreをルートからのパターンに変換したファイル
+	emptyPath    string   // 書き換え前から空だったコミットの一覧ファイル（空になったコミットのみ削除するため）
+	originalHead string   // 書き換え前のHEAD
+	trackedPaths []string // 書き換え前のHEADで追跡されていた無視対象のパス
+}
+
+// prepareIgnoreFilter はHEADの.gitignoreから無視ルールファイルを作成する
+func prepareIgnoreFilter(gitDir string) (*ignoreFilter, error) {
+	rules, err := collectIgnoreRules(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		fmt.Println("⚠️  HEADに.gitignoreがないため、--apply-gitignore は何も削除しません。")
+		return nil, nil
+	}
+
+	file, err := os.CreateTemp("", ignoreRulesPattern)
+	if err != nil {
+		return nil, fmt.Errorf("一時ファイル作成エラー: %v", err)
+	}
+	filter := &ignoreFilter{rulesPath: file.Name()}
+	_, err = file.WriteString(strings.Join(rules, "\n") + "\n")
+	file.Close()
+	if err != nil {
+		filter.cleanup()
+		return nil, fmt.Errorf("一時ファイル書き込みエラー: %v", err)
+	}
+
+	// 意図的に作成された空のコミット（--allow-empty）は削除しないよう記録する
+	emptyCommits, err := listEmptyCommits(gitDir)
+	if err != nil {
+		filter.cleanup()
+		return nil, err
+	}
+	file, err = os.CreateTemp("", ignoreRulesPattern)
+	if err != nil {
+		filter.cleanup()
+		return nil, fmt.Errorf("一時ファイル作成エラー: %v", err)
+	}
+	filter.emptyPath = file.Name()
+	_, err = file.WriteString(strings.Join(emptyCommits, "\n") + "\n")
+	file.Close()
+	if err != nil {
+		filter.cleanup()
+		return nil, fmt.Errorf("一時ファイル書き込みエラー: %v", err)
+	}
+
+	// 作業ツリーから削除されるファイルを書き換え後に復元するため記録する
+	head, _, err := utils.RunCommand(gitDir, "git", "rev-parse", "--verify", "HEAD")
+	if err == nil {
+		filter.originalHead = strings.TrimSpace(head)
+	}
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "ls-files", "-c", "-i", "-z", "-X", filter.rulesPath)
+	if err != nil {
+		filter.cleanup()
+		return nil, fmt.Errorf("無視対象ファイル取得エラー: %v\nstderr: %s", err, stderr)
+	}
+	for _, trackedPath := range strings.Split(stdout, "\x00") {
+		if trackedPath != "" {
+			filter.trackedPaths = append(filter.trackedPaths, trackedPath)
+		}
+	}
+
+	fmt.Printf("HEADの.gitignore（%d 件のルール）を全コミットに適用します。HEADで追跡中の無視対象: %d 件\n", len(rules), len(filter.trackedPaths))
+	return filter, nil
+}
+
+// indexFilter は無視ルールに一致するファイルをインデックスから削除するindex-filterを生成する
+func (f *ignoreFilter) indexFilter() string {
+	// 一致するファイルがない場合もgit rmを実行しないようにする
+	return fmt.Sprintf(`git ls-files -c -i -z -X %s | xargs -0 sh -c 'test $# -eq 0 || git rm --cached --quiet --ignore-unmatch -- "$@"' sh
+`, shellQuote(f.rulesPath))
+}
+
+// commitFilter は無視対象の削除で空になったコミットのみを取り除くcommit-filterを生成する
+// --prune-empty と異なり、書き換え前から空だったコミットは残す
+func (f *ignoreFilter) commitFilter() string {
+	return fmt.Sprintf(`if grep -qx "$GIT_COMMIT" %s; then git commit-tree "$@"; else git_commit_non_empty_tree "$@"; fi
+`, shellQuote(f.emptyPath))
+}
+
+// listEmptyCommits はツリーが親コミット（ルートコミットの場合は空のツリー）と同じコミットの一覧を返す
+// 判定はfilter-branchのgit_commit_non_empty_treeに合わせ、マージコミットは対象にしない
+func listEmptyCommits(gitDir string) ([]string, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "hash-object", "-t", "tree", os.DevNull)
+	if err != nil {
+		return nil, fmt.Errorf("空のツリー取得エラー: %v\nstderr: %s", err, stderr)
+	}
+	emptyTree := strings.TrimSpace(stdout)
+
+	stdout, stderr, err = utils.RunCommand(gitDir, "git", "log", "--format=%H %T %P", "--branches", "--tags")
+	if err != nil {
+		return nil, fmt.Errorf("コミット一覧取得エラー: %v\nstderr: %s", err, stderr)
+	}
+	trees := make(map[string]string)
+	var commits [][]string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		trees[fields[0]] = fields[1]
+		commits = append(commits, fields)
+	}
+
+	var empty []string
+	for _, fields := range commits {
+		switch len(fields) {
+		case 2:
+			if fields[1] == emptyTree {
+				empty = append(empty, fields[0])
+			}
+		case 3:
+			if trees[fields[2]] == fields[1] {
+				empty = append(empty, fields[0])
+			}
+		}
+	}
+	return empty, nil
+}
+
+// restoreWorkTree はfilter-branchが作業ツリーから削除した無視対象のファイルを書き換え前のHEADから復元する
+// 復元したファイルは追跡されず、.gitignoreにより無視される
+func (f *ignoreFilter) restoreWorkTree(gitDir string) error {
+	if f.originalHead == "" || len(f.trackedPaths) == 0 {
+		return nil
+	}
+
+	file, err := os.CreateTemp("", ignoreRulesPattern)
+	if err != nil {
+		return fmt.Errorf("一時ファイル作成エラー: %v", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(strings.Join(f.trackedPaths, "\x00"))
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("一時ファイル書き込みエラー: %v", err)
+	}
+
+	if _, stderr, err := utils.RunCommand(gitDir, "git", "restore", "--source", f.originalHead, "--worktree",
+		"--pathspec-from-file", file.Name(), "--pathspec-file-nul"); err != nil {
+		return fmt.Errorf("無視対象ファイルの復元エラー: %v\nstderr: %s", err, stderr)
+	}
+	fmt.Printf("作業ツリーの無視対象ファイル %d 件を復元しました（追跡対象からは外れています）。\n", len(f.trackedPaths))
+	return nil
+}
+
+// cleanup は一時ファイルを削除する
+func (f *ignoreFilter) cleanup() {
+	if f == nil {
+		return
+	}
+	os.Remove(f.rulesPath)
+	if f.emptyPath != "" {
+		os.Remove(f.emptyPath)
+	}
+}
+
+// collectIgnoreRules はHEADに含まれる全.gitignoreのルールをルートからのパターンに変換して返す
+func collectIgnoreRules(gitDir string) ([]string, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "ls-tree", "-r", "-z", "--name-only", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("ファイル一覧取得エラー: %v\nstderr: %s", err, stderr)
+	}
+
+	var rules []string
+	for _, file := range strings.Split(stdout, "\x00") {
+		if path.Base(file) != ".gitignore" {
+			continue
+		}
+		content, stderr, err := utils.RunCommand(gitDir, "git", "show", "HEAD:"+file)
+		if err != nil {
+			return nil, fmt.Errorf(".gitignore読み込みエラー: %s: %v\nstderr: %s", file, err, stderr)
+		}
+		dir := path.Dir(file)
+		if dir == "." {
+			dir = ""
+		}
+		rules = append(rules, translateIgnorePatterns(dir, content)...)
+	}
+	return rules, nil
+}
+
+// translateIgnorePatterns はdir配下の.gitignoreのパターンを、リポジトリのルートから評価できるパターンに変換する
+// dirが空の場合（ルートの.gitignore）はそのまま返す
+func translateIgnorePatterns(dir, content string) []string {
+	var patterns []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if dir == "" {
+			patterns = append(patterns, line)
+			continue
+		}
+
+		negate := ""
+		if strings.HasPrefix(line, "!") {
+			negate = "!"
+			line = line[1:]
+		}
+		// 末尾以外にスラッシュを含むパターンは.gitignoreのあるディレクトリからの相対パス、
+		// 含まないパターンはそのディレクトリ以下の任意の階層に一致する
+		if strings.Contains(strings.TrimSuffix(line, "/"), "/") {
+			line = "/" + dir + "/" + strings.TrimPrefix(line, "/")
+		} else {
+			line = "/" + dir + "/**/" + line
+		}
+		patterns = append(patterns, negate+line)
+	}
+	return patterns
+}
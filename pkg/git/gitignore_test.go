@@ -0,0 +1,131 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// TestTranslateIgnorePatterns はサブディレクトリの.gitignoreのパターン変換をテストする
+func TestTranslateIgnorePatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "ルートの.gitignore",
+			dir:      "",
+			content:  "# comment\nnode_modules/\n\n.DS_Store\n",
+			expected: []string{"node_modules/", ".DS_Store"},
+		},
+		{
+			name:     "スラッシュを含まないパターン",
+			dir:      "web",
+			content:  "*.log\ndist/\n",
+			expected: []string{"/web/**/*.log", "/web/**/dist/"},
+		},
+		{
+			name:     "スラッシュを含むパターン",
+			dir:      "web",
+			content:  "/build\ncache/tmp\n",
+			expected: []string{"/web/build", "/web/cache/tmp"},
+		},
+		{
+			name:     "否定パターン",
+			dir:      "web",
+			content:  "!keep.log\n",
+			expected: []string{"!/web/**/keep.log"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := translateIgnorePatterns(tt.dir, tt.content)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("期待値: %v, 実際: %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+// commitFiles はファイルを作成してコミットする
+func commitFiles(t *testing.T, repo, message string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("ディレクトリ作成エラー: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("ファイル作成エラー: %v", err)
+		}
+	}
+	for _, args := range [][]string{{"git", "add", "-f", "."}, {"git", "commit", "-m", message}} {
+		if _, stderr, err := utils.RunCommand(repo, args[0], args[1:]...); err != nil {
+			t.Fatalf("%v エラー: %v, stderr: %s", args, err, stderr)
+		}
+	}
+}
+
+// TestRewriteHistoryApplyGitignore は--apply-gitignoreの動作をテストする
+func TestRewriteHistoryApplyGitignore(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commitFiles(t, repo, "add deps", map[string]string{
+		"node_modules/lib/index.js": "module.exports = 1\n",
+		".DS_Store":                 "junk",
+		"src/main.js":               "console.log(1)\n",
+	})
+	commitFiles(t, repo, "update deps", map[string]string{
+		"node_modules/lib/index.js": "module.exports = 2\n",
+	})
+	// 意図的に作成された空のコミットは残す
+	if _, stderr, err := utils.RunCommand(repo, "git", "commit", "--allow-empty", "-m", "release marker"); err != nil {
+		t.Fatalf("空のコミット作成エラー: %v, stderr: %s", err, stderr)
+	}
+	commitFiles(t, repo, "add gitignore", map[string]string{
+		".gitignore":     "node_modules/\n.DS_Store\n",
+		"src/.gitignore": "*.log\n",
+		"src/debug.log":  "debug\n",
+	})
+
+	opts := RewriteOptions{ApplyGitignore: true}
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", opts); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	// 無視対象のファイルはどのコミットにも含まれない
+	stdout, _, err := utils.RunCommand(repo, "git", "log", "--branches", "--tags", "--name-only", "--format=")
+	if err != nil {
+		t.Fatalf("git log エラー: %v", err)
+	}
+	for _, ignored := range []string{"node_modules/lib/index.js", ".DS_Store", "src/debug.log"} {
+		if contains(stdout, ignored) {
+			t.Errorf("%s が履歴に残っています:\n%s", ignored, stdout)
+		}
+	}
+	if !contains(stdout, "src/main.js") {
+		t.Errorf("無視対象でないファイルが削除されました:\n%s", stdout)
+	}
+
+	// 無視対象のファイルのみを変更したコミットは除かれ、元から空のコミットは残る
+	subjects := logSubjects(t, repo, "HEAD")
+	if strings.Join(subjects, ",") != "add gitignore,release marker,add deps,initial" {
+		t.Errorf("空になったコミットのみが除かれていません: %v", subjects)
+	}
+
+	// 作業ツリーのファイルは残り、追跡されない
+	if !utils.FileExists(filepath.Join(repo, "node_modules", "lib", "index.js")) {
+		t.Error("作業ツリーの無視対象ファイルが削除されました")
+	}
+	status, _, _ := utils.RunCommand(repo, "git", "status", "--porcelain")
+	if strings.TrimSpace(status) != "" {
+		t.Errorf("書き換え後の作業ツリーがクリーンではありません:\n%s", status)
+	}
+}
@@ -5,12 +5,37 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"git-rewrite/pkg/utils"
 )
 
+// RewriteOptions は履歴書き換えの追加オプションを表す
+type RewriteOptions struct {
+	Condition CommitCondition // author/emailを書き換えるコミットの条件
+	Progress  *BatchProgress  // 複数リポジトリにまたがる全体の進捗（nilの場合はリポジトリ単位のみ表示）
+
+	Anonymizer *Anonymizer // 設定されている場合、全identityを決定的な仮名に置き換える
+
+	SquashHistory  bool      // 各ブランチ・タグを現在のツリーのみを持つ1つのルートコミットにする
+	TruncateBefore time.Time // この日時より前の履歴を1つのルートコミットに畳み込む
+
+	Normalization  TreeNormalization // 各コミットのツリーに適用する正規化
+	ApplyGitignore bool              // HEADの.gitignoreに一致するファイルを全コミットから削除し、空になったコミットを除く
+
+	RefRenamer RefRenamer // 設定されている場合、書き換え後にブランチ・タグの名前を変更する
+
+	LFSMigration *LFSMigration // 設定されている場合、書き換え後に条件に一致するファイルをGit LFSに移行する
+}
+
 // RewriteHistory はGit履歴のauthor/emailを書き換える
 func RewriteHistory(gitDir, githubUser, githubEmail string) error {
+	return RewriteHistoryWithOptions(gitDir, githubUser, githubEmail, RewriteOptions{})
+}
+
+// RewriteHistoryWithOptions はオプションを指定してGit履歴のauthor/emailを書き換える
+func RewriteHistoryWithOptions(gitDir, githubUser, githubEmail string, opts RewriteOptions) error {
 	fmt.Printf("[1/2] Git履歴のauthor/emailを書き換えます...\n")
 
 	// 現在のディレクトリがGitリポジトリかチェック
@@ -19,6 +44,13 @@ func RewriteHistory(gitDir, githubUser, githubEmail string) error {
 		return fmt.Errorf("エラー: %s はGitリポジトリではありません", gitDir)
 	}
 
+	// 履歴を書き換えてからLFSが使えないことに気付かないよう、先に確認する
+	if opts.LFSMigration != nil {
+		if err := CheckLFSAvailable(gitDir); err != nil {
+			return err
+		}
+	}
+
 	// 既存のバックアップが存在する場合は削除
 	backupPath := filepath.Join(gitDir, ".git", "refs", "original")
 	if utils.FileExists(backupPath) {
@@ -34,27 +66,160 @@ func RewriteHistory(gitDir, githubUser, githubEmail string) error {
 	env = append(env, "LANG=C.UTF-8")
 	env = append(env, "FILTER_BRANCH_SQUELCH_WARNING=1")
 
+	// 書き換え対象コミットの条件
+	commitListPath, err := writeCommitList(gitDir, &opts.Condition)
+	if err != nil {
+		return err
+	}
+	if commitListPath != "" {
+		defer os.Remove(commitListPath)
+	}
+	if !opts.Condition.IsEmpty() {
+		fmt.Printf("書き換え対象: %s\n", opts.Condition.String())
+	}
+
 	// git filter-branchコマンドを構築
-	envFilter := fmt.Sprintf(`
-if [ "$GIT_COMMITTER_EMAIL" != "%s" ] || [ "$GIT_COMMITTER_NAME" != "%s" ]; then
-    export GIT_COMMITTER_NAME="%s"
-    export GIT_COMMITTER_EMAIL="%s"
-fi
-if [ "$GIT_AUTHOR_EMAIL" != "%s" ] || [ "$GIT_AUTHOR_NAME" != "%s" ]; then
-    export GIT_AUTHOR_NAME="%s"
-    export GIT_AUTHOR_EMAIL="%s"
+	identityFilter := fmt.Sprintf(`
+if [ "$rewrite_identity" = 1 ]; then
+    if [ "$GIT_COMMITTER_EMAIL" != "%s" ] || [ "$GIT_COMMITTER_NAME" != "%s" ]; then
+        export GIT_COMMITTER_NAME="%s"
+        export GIT_COMMITTER_EMAIL="%s"
+    fi
+    if [ "$GIT_AUTHOR_EMAIL" != "%s" ] || [ "$GIT_AUTHOR_NAME" != "%s" ]; then
+        export GIT_AUTHOR_NAME="%s"
+        export GIT_AUTHOR_EMAIL="%s"
+    fi
 fi
 `, githubEmail, githubUser, githubUser, githubEmail,
 		githubEmail, githubUser, githubUser, githubEmail)
+	var trailers *trailerAnonymization
+	if opts.Anonymizer != nil {
+		identityFilter, err = opts.Anonymizer.buildFilter(gitDir)
+		if err != nil {
+			return err
+		}
+		// Signed-off-byなどのトレーラーに記録されたidentityも置き換える
+		trailers, err = opts.Anonymizer.prepareTrailerAnonymization(gitDir)
+		if err != nil {
+			return err
+		}
+		defer trailers.cleanup()
+	}
+	envFilter := buildConditionFilter(&opts.Condition, commitListPath) + identityFilter
+
+	// i18n.commitEncodingでUTF-8以外が記録されたコミットの変換
+	encoding, err := prepareEncodingConversion(gitDir)
+	if err != nil {
+		return err
+	}
+	defer encoding.cleanup()
+	if encoding != nil {
+		// 名前の変換はidentityの照合より前に行う
+		envFilter = encoding.envFilter() + envFilter
+	}
+
+	// 履歴の畳み込み（replace参照をfilter-branchで履歴に反映する）
+	grafts, err := prepareHistoryGrafts(gitDir, &opts)
+	if err != nil {
+		return err
+	}
+	defer grafts.cleanup(gitDir)
+
+	// ツリーの正規化
+	normalizer, err := prepareTreeNormalization(gitDir, opts.Normalization)
+	if err != nil {
+		return err
+	}
+	defer normalizer.cleanup()
+
+	// HEADの.gitignoreを履歴全体に適用
+	var ignore *ignoreFilter
+	if opts.ApplyGitignore {
+		ignore, err = prepareIgnoreFilter(gitDir)
+		if err != nil {
+			return err
+		}
+		defer ignore.cleanup()
+	}
 
-	cmd := exec.Command("git", "filter-branch", "-f", "--env-filter", envFilter,
-		"--tag-name-filter", "cat", "--", "--branches", "--tags")
+	// 書き換え後のコミットにはエンコーディングヘッダーを付与しない（UTF-8として記録する）
+	args := []string{"-c", "i18n.commitEncoding=UTF-8", "filter-branch", "-f", "--env-filter", envFilter}
+	var msgFilters []string
+	if encoding != nil {
+		msgFilters = append(msgFilters, "("+encoding.msgFilter()+")")
+	}
+	if trailers != nil {
+		msgFilters = append(msgFilters, "("+trailers.msgFilter()+")")
+	}
+	if len(msgFilters) > 0 {
+		args = append(args, "--msg-filter", strings.Join(msgFilters, " | "))
+	}
+	if normalizer != nil {
+		args = append(args, "--tree-filter", normalizer.treeFilter())
+	}
+	if ignore != nil {
+		args = append(args, "--index-filter", ignore.indexFilter(), "--commit-filter", ignore.commitFilter())
+	}
+	args = append(args, "--tag-name-filter", "cat", "--", "--branches", "--tags")
+
+	cmd := exec.Command("git", args...)
 	cmd.Dir = gitDir
 	cmd.Env = env
 
-	output, err := cmd.CombinedOutput()
+	// 出力を逐次解析して進捗を表示する
+	reporter := NewProgressReporter(opts.Progress)
+	writer := &progressWriter{reporter: reporter}
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	err = cmd.Run()
+	reporter.Finish()
 	if err != nil {
-		return fmt.Errorf("git filter-branchの実行に失敗しました: %v\n出力: %s", err, utils.SafeDecode(output))
+		return fmt.Errorf("git filter-branchの実行に失敗しました: %v\n出力: %s", err, utils.SafeDecode(writer.output.Bytes()))
+	}
+
+	if encoding != nil {
+		encoding.report()
+	}
+	if opts.Anonymizer != nil {
+		count, err := opts.Anonymizer.anonymizeTaggers(gitDir)
+		if err != nil {
+			return fmt.Errorf("タグのtaggerの匿名化に失敗しました: %v", err)
+		}
+		if count > 0 {
+			fmt.Printf("%d 個の注釈付きタグのtaggerを仮名に置き換えました。\n", count)
+		}
+	}
+	if ignore != nil {
+		if err := ignore.restoreWorkTree(gitDir); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+
+	// 大きなファイルのLFSへの移行
+	if opts.LFSMigration != nil {
+		if err := MigrateToLFS(gitDir, opts.LFSMigration); err != nil {
+			return err
+		}
+	}
+
+	// ブランチ・タグ名の変更（以降のプッシュは変更後の名前で行われる）
+	if opts.RefRenamer != nil {
+		changes, err := RenameRefs(gitDir, opts.RefRenamer)
+		if err != nil {
+			return fmt.Errorf("ブランチ・タグ名の変更に失敗しました: %v", err)
+		}
+		for _, change := range changes {
+			fmt.Printf("  %s\n", FormatRefChange(change))
+		}
+		fmt.Printf("%d 個のブランチ・タグ名を変更しました。\n", len(changes))
+	}
+
+	if opts.Anonymizer != nil {
+		if err := opts.Anonymizer.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("仮名の対応表を保存しました: %s\n", opts.Anonymizer.MapPath)
 	}
 
 	fmt.Printf("✅ Git履歴の書き換えが完了しました。\n")
@@ -0,0 +1,122 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"git-rewrite/pkg/utils"
+)
+
+// identityの種別
+const (
+	IdentityRoleAuthor    = "author"
+	IdentityRoleCommitter = "committer"
+	IdentityRoleTagger    = "tagger"
+)
+
+// IdentityStat はリポジトリ内のidentityごとの集計結果を表す
+type IdentityStat struct {
+	Repository string    `json:"repository"`
+	Role       string    `json:"role"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	Count      int       `json:"count"`
+	First      time.Time `json:"first"`
+	Last       time.Time `json:"last"`
+}
+
+// CollectIdentities はリポジトリ内のauthor・committer・taggerのidentityを集計する
+func CollectIdentities(gitDir string) ([]IdentityStat, error) {
+	gitPath := filepath.Join(gitDir, ".git")
+	if !utils.FileExists(gitPath) {
+		return nil, fmt.Errorf("エラー: %s はGitリポジトリではありません", gitDir)
+	}
+
+	stats := make(map[string]*IdentityStat)
+	record := func(role, name, email, timestamp string) {
+		seconds, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+		if err != nil {
+			return
+		}
+		date := time.Unix(seconds, 0)
+
+		key := role + "\x00" + name + "\x00" + email
+		stat, exists := stats[key]
+		if !exists {
+			stat = &IdentityStat{Repository: gitDir, Role: role, Name: name, Email: email, First: date, Last: date}
+			stats[key] = stat
+		}
+		stat.Count++
+		if date.Before(stat.First) {
+			stat.First = date
+		}
+		if date.After(stat.Last) {
+			stat.Last = date
+		}
+	}
+
+	// コミットのauthor・committer
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "log", "--branches", "--tags",
+		"--format=%an%x00%ae%x00%at%x00%cn%x00%ce%x00%ct")
+	if err != nil {
+		// コミットが存在しないリポジトリは空の結果とする
+		if _, _, headErr := utils.RunCommand(gitDir, "git", "rev-parse", "--verify", "--quiet", "HEAD"); headErr != nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("コミット一覧取得エラー: %v\nstderr: %s", err, stderr)
+	}
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 6 {
+			continue
+		}
+		record(IdentityRoleAuthor, fields[0], fields[1], fields[2])
+		record(IdentityRoleCommitter, fields[3], fields[4], fields[5])
+	}
+
+	// 注釈付きタグのtagger
+	stdout, stderr, err = utils.RunCommand(gitDir, "git", "for-each-ref", "refs/tags",
+		"--format=%(taggername)%00%(taggeremail)%00%(taggerdate:unix)")
+	if err != nil {
+		return nil, fmt.Errorf("タグ一覧取得エラー: %v\nstderr: %s", err, stderr)
+	}
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 3 || fields[0] == "" {
+			// 軽量タグにはtaggerが存在しない
+			continue
+		}
+		record(IdentityRoleTagger, fields[0], strings.Trim(fields[1], "<>"), fields[2])
+	}
+
+	result := make([]IdentityStat, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Role != result[j].Role {
+			return roleOrder(result[i].Role) < roleOrder(result[j].Role)
+		}
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Email < result[j].Email
+	})
+	return result, nil
+}
+
+// roleOrder は表示順序のための種別の並び順を返す
+func roleOrder(role string) int {
+	switch role {
+	case IdentityRoleAuthor:
+		return 0
+	case IdentityRoleCommitter:
+		return 1
+	default:
+		return 2
+	}
+}
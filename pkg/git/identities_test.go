@@ -0,0 +1,81 @@
+package git
+
+import (
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// TestCollectIdentities はauthor・committer・taggerの集計をテストする
+func TestCollectIdentities(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commitWithDate(t, repo, "second", "2020-01-01T00:00:00", "alice@corp.example")
+	commitWithDate(t, repo, "third", "2021-06-01T00:00:00", "alice@corp.example")
+
+	if _, stderr, err := utils.RunCommand(repo, "git", "-c", "user.email=release@corp.example",
+		"tag", "-a", "v1.0", "-m", "release"); err != nil {
+		t.Fatalf("git tag エラー: %v, stderr: %s", err, stderr)
+	}
+	if _, stderr, err := utils.RunCommand(repo, "git", "tag", "lightweight"); err != nil {
+		t.Fatalf("git tag エラー: %v, stderr: %s", err, stderr)
+	}
+
+	stats, err := CollectIdentities(repo)
+	if err != nil {
+		t.Fatalf("CollectIdentitiesでエラーが発生しました: %v", err)
+	}
+
+	find := func(role, email string) *IdentityStat {
+		for i := range stats {
+			if stats[i].Role == role && stats[i].Email == email {
+				return &stats[i]
+			}
+		}
+		return nil
+	}
+
+	alice := find(IdentityRoleAuthor, "alice@corp.example")
+	if alice == nil || alice.Count != 2 {
+		t.Fatalf("authorの集計が正しくありません: %+v", stats)
+	}
+	if alice.First.Year() != 2020 || alice.Last.Year() != 2021 {
+		t.Errorf("最初と最後の日付が正しくありません: %v, %v", alice.First, alice.Last)
+	}
+	if committer := find(IdentityRoleCommitter, "alice@corp.example"); committer == nil || committer.Count != 2 {
+		t.Errorf("committerの集計が正しくありません: %+v", stats)
+	}
+	if author := find(IdentityRoleAuthor, "test@example.com"); author == nil || author.Count != 1 {
+		t.Errorf("初期コミットのauthorが集計されていません: %+v", stats)
+	}
+
+	// 注釈付きタグのみtaggerとして集計される
+	tagger := find(IdentityRoleTagger, "release@corp.example")
+	if tagger == nil || tagger.Count != 1 {
+		t.Errorf("taggerの集計が正しくありません: %+v", stats)
+	}
+
+	// 種別の順に並んでいる
+	if stats[0].Role != IdentityRoleAuthor || stats[len(stats)-1].Role != IdentityRoleTagger {
+		t.Errorf("集計結果の並び順が正しくありません: %+v", stats)
+	}
+}
+
+// TestCollectIdentitiesEmptyRepository はコミットのないリポジトリをテストする
+func TestCollectIdentitiesEmptyRepository(t *testing.T) {
+	repo := t.TempDir()
+	if _, stderr, err := utils.RunCommand(repo, "git", "init"); err != nil {
+		t.Fatalf("git init エラー: %v, stderr: %s", err, stderr)
+	}
+
+	stats, err := CollectIdentities(repo)
+	if err != nil {
+		t.Fatalf("空のリポジトリでエラーが発生しました: %v", err)
+	}
+	if len(stats) != 0 {
+		t.Errorf("空のリポジトリで集計結果が返されました: %+v", stats)
+	}
+
+	if _, err := CollectIdentities(t.TempDir()); err == nil {
+		t.Error("Gitリポジトリでないディレクトリでエラーが期待されました")
+	}
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"git-rewrite/pkg/utils"
+)
+
+// lfsSizePattern はファイルサイズの指定（例: 100MB, 1.5GiB）に一致する
+var lfsSizePattern = regexp.MustCompile(`(?i)^[0-9]+(\.[0-9]+)?\s*(b|kb|kib|mb|mib|gb|gib|tb|tib)$`)
+
+// LFSMigration はGit LFSに移行するファイルの条件を表す
+// PatternsとAboveのどちらか一方のみを指定する
+type LFSMigration struct {
+	Patterns []string // 移行するファイルのglobパターン（例: *.psd）
+	Above    string   // このサイズを超えるファイルを移行する（例: 100mb）
+}
+
+// ParseLFSMigration は --lfs-migrate の値を解析する
+// サイズ（例: 100MB）またはカンマ区切りのglobパターン（例: *.psd,*.zip）を指定できる
+func ParseLFSMigration(spec string) (*LFSMigration, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("移行するファイルのパターンまたはサイズを指定してください")
+	}
+	if lfsSizePattern.MatchString(spec) {
+		return &LFSMigration{Above: strings.ToLower(strings.Join(strings.Fields(spec), ""))}, nil
+	}
+
+	migration := &LFSMigration{}
+	for _, pattern := range strings.Split(spec, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		// git lfs migrate はサイズとパターンを同時に指定できない
+		if lfsSizePattern.MatchString(pattern) {
+			return nil, fmt.Errorf("サイズとglobパターンは同時に指定できません: %s", spec)
+		}
+		migration.Patterns = append(migration.Patterns, pattern)
+	}
+	if len(migration.Patterns) == 0 {
+		return nil, fmt.Errorf("移行するファイルのパターンまたはサイズを指定してください")
+	}
+	return migration, nil
+}
+
+// String は移行条件を表示用の文字列に変換する
+func (m *LFSMigration) String() string {
+	if m.Above != "" {
+		return fmt.Sprintf("%s を超えるファイル", m.Above)
+	}
+	return strings.Join(m.Patterns, ", ")
+}
+
+// migrateArgs はgit lfs migrate importの引数を生成する
+// refsには移行対象の参照を指定する（refs/originalなどのバックアップは含めない）
+func (m *LFSMigration) migrateArgs(refs []string) []string {
+	args := []string{"lfs", "migrate", "import"}
+	if m.Above != "" {
+		args = append(args, "--above="+m.Above)
+	} else {
+		args = append(args, "--include="+strings.Join(m.Patterns, ","))
+	}
+	for _, ref := range refs {
+		args = append(args, "--include-ref="+ref)
+	}
+	return args
+}
+
+// CheckLFSAvailable はgit-lfsが利用可能かを確認する
+func CheckLFSAvailable(gitDir string) error {
+	if _, _, err := utils.RunCommand(gitDir, "git", "lfs", "version"); err != nil {
+		return fmt.Errorf("git-lfsが見つかりません。--lfs-migrate を使用するにはGit LFSをインストールしてください: %v", err)
+	}
+	return nil
+}
+
+// MigrateToLFS は全ブランチ・タグの履歴中で条件に一致するファイルをLFSポインタに置き換える
+// 各コミットの.gitattributesにはLFSの設定が追加される
+func MigrateToLFS(gitDir string, migration *LFSMigration) error {
+	fmt.Printf("Git LFSに移行しています: %s\n", migration.String())
+
+	// プッシュ時にLFSオブジェクトを扱えるようにリポジトリにLFSを設定する
+	if _, stderr, err := utils.RunCommand(gitDir, "git", "lfs", "install", "--local"); err != nil {
+		return fmt.Errorf("git lfs install エラー: %v\nstderr: %s", err, stderr)
+	}
+
+	var refs []string
+	for _, prefix := range []string{branchRefPrefix, tagRefPrefix} {
+		prefixRefs, err := listRefs(gitDir, prefix)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, prefixRefs...)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", migration.migrateArgs(refs)...)
+	if err != nil {
+		return fmt.Errorf("git lfs migrate エラー: %v\nstdout: %s\nstderr: %s", err, stdout, stderr)
+	}
+
+	fmt.Println("✅ Git LFSへの移行が完了しました。")
+	return nil
+}
+
+// PushLFSObjects はローカルの全参照から参照されるLFSオブジェクトをリモートにアップロードする
+// Gitのプッシュより前に実行し、ポインタのみがプッシュされる状態を避ける
+func PushLFSObjects(gitDir, remote string, auth PushURLProvider) error {
+	fmt.Println("📦 LFSオブジェクトをアップロードしています...")
+	stdout, stderr, err := runGitWithAuth(gitDir, remote, auth, "lfs", "push", "--all", remote)
+	if err != nil {
+		return fmt.Errorf("LFSオブジェクトのアップロードエラー: %v\nstderr: %s", err, stderr)
+	}
+	if stdout != "" {
+		fmt.Printf("LFSアップロード結果: %s\n", stdout)
+	}
+	fmt.Println("✅ LFSオブジェクトのアップロードが完了しました。")
+	return nil
+}
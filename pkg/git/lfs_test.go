@@ -0,0 +1,142 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// TestParseLFSMigration は --lfs-migrate の値の解析をテストする
+func TestParseLFSMigration(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected *LFSMigration
+		wantErr  bool
+	}{
+		{"100MB", &LFSMigration{Above: "100mb"}, false},
+		{"1.5 GiB", &LFSMigration{Above: "1.5gib"}, false},
+		{"*.psd", &LFSMigration{Patterns: []string{"*.psd"}}, false},
+		{"*.psd, assets/**/*.zip", &LFSMigration{Patterns: []string{"*.psd", "assets/**/*.zip"}}, false},
+		{"*.psd,100MB", nil, true},
+		{" , ", nil, true},
+		{"", nil, true},
+	}
+
+	for _, tt := range tests {
+		migration, err := ParseLFSMigration(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: エラーが期待されましたが、エラーが発生しませんでした", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: 予期しないエラー: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(migration, tt.expected) {
+			t.Errorf("%q: 期待値 %+v, 実際 %+v", tt.spec, tt.expected, migration)
+		}
+	}
+}
+
+// TestLFSMigrationArgs はgit lfs migrate importの引数が移行対象の参照のみを含むことをテストする
+func TestLFSMigrationArgs(t *testing.T) {
+	refs := []string{"refs/heads/main", "refs/tags/v1.0"}
+
+	args := (&LFSMigration{Above: "100mb"}).migrateArgs(refs)
+	expected := []string{"lfs", "migrate", "import", "--above=100mb", "--include-ref=refs/heads/main", "--include-ref=refs/tags/v1.0"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("期待値 %v, 実際 %v", expected, args)
+	}
+
+	args = (&LFSMigration{Patterns: []string{"*.psd", "*.zip"}}).migrateArgs(refs[:1])
+	expected = []string{"lfs", "migrate", "import", "--include=*.psd,*.zip", "--include-ref=refs/heads/main"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("期待値 %v, 実際 %v", expected, args)
+	}
+}
+
+// lfsPointerHeader はLFSポインタファイルの先頭行
+const lfsPointerHeader = "version https://git-lfs.github.com/spec/v1"
+
+// largeFileHook は1KBを超えるblobを含むプッシュをGitHubと同じGH001メッセージで拒否するpre-receiveフック
+const largeFileHook = `#!/bin/sh
+while read old new ref; do
+    git rev-list --objects "$new" | while read object path; do
+        if [ "$(git cat-file -t "$object")" = blob ] && [ "$(git cat-file -s "$object")" -gt 1024 ]; then
+            echo "remote: error: GH001: Large files detected. You may want to try Git Large File Storage." >&2
+            exit 1
+        fi
+    done || exit 1
+done
+`
+
+// TestMigrateToLFS は大きなファイルが履歴全体でLFSポインタに置き換わり、GH001で拒否されずにプッシュできることをテストする
+func TestMigrateToLFS(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	if err := CheckLFSAvailable(repo); err != nil {
+		t.Skipf("git-lfsがインストールされていないためスキップします: %v", err)
+	}
+
+	commitFiles(t, repo, "add large file", map[string]string{"large.bin": strings.Repeat("a", 4096)})
+	if _, stderr, err := utils.RunCommand(repo, "git", "tag", "v1.0"); err != nil {
+		t.Fatalf("git tag エラー: %v, stderr: %s", err, stderr)
+	}
+	commitFiles(t, repo, "update large file", map[string]string{"large.bin": strings.Repeat("b", 4096)})
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if _, stderr, err := utils.RunCommand(repo, "git", "init", "--bare", remote); err != nil {
+		t.Fatalf("git init --bare エラー: %v, stderr: %s", err, stderr)
+	}
+	if err := os.WriteFile(filepath.Join(remote, "hooks", "pre-receive"), []byte(largeFileHook), 0755); err != nil {
+		t.Fatalf("フック作成エラー: %v", err)
+	}
+	if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", "origin", remote); err != nil {
+		t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+	}
+
+	// 移行前はGH001で拒否される
+	var largeFileErr *LargeFileError
+	if err := PushAllBranchesAndTags(repo, ""); !errors.As(err, &largeFileErr) {
+		t.Fatalf("移行前のプッシュでLargeFileErrorが期待されました: %v", err)
+	}
+
+	if err := MigrateToLFS(repo, &LFSMigration{Above: "1kb"}); err != nil {
+		t.Fatalf("MigrateToLFSでエラーが発生しました: %v", err)
+	}
+
+	for _, rev := range []string{"HEAD", "HEAD~1", "v1.0"} {
+		content, stderr, err := utils.RunCommand(repo, "git", "show", rev+":large.bin")
+		if err != nil {
+			t.Fatalf("git show エラー: %v, stderr: %s", err, stderr)
+		}
+		if !strings.HasPrefix(content, lfsPointerHeader) {
+			t.Errorf("%s の large.bin がLFSポインタに置き換わっていません: %q", rev, content)
+		}
+
+		attributes, stderr, err := utils.RunCommand(repo, "git", "show", rev+":.gitattributes")
+		if err != nil {
+			t.Fatalf("git show エラー: %v, stderr: %s", err, stderr)
+		}
+		if !strings.Contains(attributes, "filter=lfs") {
+			t.Errorf("%s の.gitattributesにLFSの設定が追加されていません: %q", rev, attributes)
+		}
+	}
+
+	// LFSオブジェクトのアップロード（pre-pushフック）はこのテストの対象外
+	if err := os.Remove(filepath.Join(repo, ".git", "hooks", "pre-push")); err != nil && !os.IsNotExist(err) {
+		t.Fatalf("pre-pushフック削除エラー: %v", err)
+	}
+	if err := PushAllBranchesAndTags(repo, ""); err != nil {
+		if errors.As(err, &largeFileErr) {
+			t.Fatalf("移行後のプッシュがGH001で拒否されました: %v", err)
+		}
+		t.Fatalf("移行後のプッシュでエラーが発生しました: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git-rewrite/pkg/utils"
+)
+
+// defaultEOLAttributes は.gitattributesが存在しない場合に使用する改行コードの設定
+const defaultEOLAttributes = "* text=auto\n"
+
+// TreeNormalization は履歴中のツリーに適用する正規化を表す
+type TreeNormalization struct {
+	EOL                bool     // .gitattributesに従ってCRLFをLFに正規化する
+	StripExecBits      bool     // シバン（#!）で始まらないファイルの実行権限を外す
+	TrailingWhitespace []string // 行末の空白を削除するファイルのglobパターン（テキストファイルのみ対象）
+}
+
+// IsEmpty は正規化が指定されていないかどうかを返す
+func (n *TreeNormalization) IsEmpty() bool {
+	return !n.EOL && !n.StripExecBits && len(n.TrailingWhitespace) == 0
+}
+
+// String は正規化の内容を表示用の文字列に変換する
+func (n *TreeNormalization) String() string {
+	var parts []string
+	if n.EOL {
+		parts = append(parts, "改行コード（CRLF→LF）")
+	}
+	if n.StripExecBits {
+		parts = append(parts, "不要な実行権限の削除")
+	}
+	if len(n.TrailingWhitespace) > 0 {
+		parts = append(parts, fmt.Sprintf("行末の空白の削除（%s）", strings.Join(n.TrailingWhitespace, ", ")))
+	}
+	if len(parts) == 0 {
+		return "なし"
+	}
+	return strings.Join(parts, "、")
+}
+
+// treeNormalizer は正規化のために一時的に変更したリポジトリの設定を保持する
+type treeNormalizer struct {
+	normalization      TreeNormalization
+	attributesPath     string // .git/info/attributes
+	originalAttributes []byte // 変更前の.git/info/attributes（存在しなかった場合はnil）
+}
+
+// prepareTreeNormalization はツリーの正規化を準備する
+// 改行コードの正規化では、現在のHEADの.gitattributesを過去の全コミットに適用するため.git/info/attributesに設定する
+func prepareTreeNormalization(gitDir string, normalization TreeNormalization) (*treeNormalizer, error) {
+	if normalization.IsEmpty() {
+		return nil, nil
+	}
+
+	fmt.Printf("ツリーを正規化します: %s\n", normalization.String())
+	fmt.Println("（各コミットをチェックアウトして処理するため、時間がかかる場合があります）")
+
+	normalizer := &treeNormalizer{normalization: normalization}
+	if !normalization.EOL {
+		return normalizer, nil
+	}
+
+	attributes, _, err := utils.RunCommand(gitDir, "git", "show", "HEAD:.gitattributes")
+	if err != nil || strings.TrimSpace(attributes) == "" {
+		fmt.Printf("⚠️  HEADに.gitattributesがないため、'%s' として改行コードを正規化します。\n", strings.TrimSpace(defaultEOLAttributes))
+		attributes = defaultEOLAttributes
+	}
+
+	normalizer.attributesPath = filepath.Join(gitDir, ".git", "info", "attributes")
+	if original, err := os.ReadFile(normalizer.attributesPath); err == nil {
+		normalizer.originalAttributes = original
+		// リポジトリ固有の設定は現在のポリシーより優先する
+		attributes += "\n" + string(original)
+	}
+	if err := os.MkdirAll(filepath.Dir(normalizer.attributesPath), 0755); err != nil {
+		return nil, fmt.Errorf("ディレクトリ作成エラー: %v", err)
+	}
+	if err := os.WriteFile(normalizer.attributesPath, []byte(attributes), 0644); err != nil {
+		return nil, fmt.Errorf("attributesファイル書き込みエラー: %v", err)
+	}
+	return normalizer, nil
+}
+
+// treeFilter は各コミットのツリーを正規化するtree-filterを生成する
+// 変更したファイルはfilter-branchによって.gitattributesの設定に従ってインデックスに再登録される
+func (n *treeNormalizer) treeFilter() string {
+	var script strings.Builder
+	if n.normalization.StripExecBits {
+		// 空白や改行を含むファイル名もそのまま扱えるよう、NUL区切りで受け取る（各要素は "mode object stage<TAB>path"）
+		script.WriteString(`git ls-files -s -z | xargs -0 sh -c '
+tab=$(printf "\t")
+for entry; do
+    path=${entry#*"$tab"}
+    if [ "${entry%% *}" = 100755 ] && [ "$(head -c 2 "$path")" != "#!" ]; then
+        chmod a-x "$path"
+    fi
+done' sh
+`)
+	}
+	if len(n.normalization.TrailingWhitespace) > 0 {
+		// 指定したパターンに一致するファイルのみ対象とし、バイナリファイルは除く（git grep -I）。CRLFの改行は維持する
+		// 一致するファイルがない場合はperlを実行しない（引数なしのperlは標準入力を読み込むため）
+		script.WriteString(`cr=$(printf '\r')
+git grep -I -l -z -E "[[:blank:]]+${cr}?\$" --`)
+		for _, pattern := range n.normalization.TrailingWhitespace {
+			script.WriteString(" " + shellQuote(whitespacePathspec(pattern)))
+		}
+		script.WriteString(` | xargs -0 -r perl -pi -e 's/[ \t]+(\r?)$/$1/'
+`)
+	}
+	if n.normalization.EOL {
+		script.WriteString("git add --renormalize . >/dev/null 2>&1\n")
+	}
+	return script.String()
+}
+
+// whitespacePathspec はglobパターンをgit grepのpathspecに変換する
+// .gitattributesと同様に、スラッシュを含まないパターンは任意のディレクトリのファイル名に一致させる
+func whitespacePathspec(pattern string) string {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return ":(glob)" + strings.TrimPrefix(pattern, "/")
+}
+
+// cleanup は.git/info/attributesを元に戻す
+func (n *treeNormalizer) cleanup() {
+	if n == nil || n.attributesPath == "" {
+		return
+	}
+	if n.originalAttributes != nil {
+		os.WriteFile(n.attributesPath, n.originalAttributes, 0644)
+		return
+	}
+	os.Remove(n.attributesPath)
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// TestRewriteHistoryTreeNormalization は改行コード・実行権限・行末の空白の正規化をテストする
+func TestRewriteHistoryTreeNormalization(t *testing.T) {
+	repo := setupPreflightRepo(t)
+
+	files := map[string]struct {
+		content string
+		mode    os.FileMode
+	}{
+		"crlf.txt":        {"first line \r\nsecond\t\r\n", 0644},
+		"space.txt":       {"trailing   \nkeep\n", 0644},
+		"docs/nested.txt": {"nested \n", 0644},
+		"notes.md":        {"not selected  \n", 0644},
+		"script.sh":       {"#!/bin/sh\necho ok\n", 0755},
+		"data.txt":        {"not a script\n", 0755},
+		"tool ":           {"trailing space in name\n", 0755},
+	}
+	for name, file := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(repo, name)), 0755)
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(file.content), file.mode); err != nil {
+			t.Fatalf("ファイル作成エラー: %v", err)
+		}
+	}
+	for _, args := range [][]string{{"git", "add", "."}, {"git", "commit", "-m", "legacy files"}} {
+		if _, stderr, err := utils.RunCommand(repo, args[0], args[1:]...); err != nil {
+			t.Fatalf("%v エラー: %v, stderr: %s", args, err, stderr)
+		}
+	}
+
+	opts := RewriteOptions{Normalization: TreeNormalization{EOL: true, StripExecBits: true, TrailingWhitespace: []string{"*.txt"}}}
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", opts); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	expected := map[string]string{
+		"crlf.txt":        "first line\nsecond\n",
+		"space.txt":       "trailing\nkeep\n",
+		"docs/nested.txt": "nested\n",
+		"notes.md":        "not selected  \n", // パターンに一致しないファイルは変更しない
+	}
+	for name, content := range expected {
+		actual, _, err := utils.RunCommand(repo, "git", "show", "HEAD:"+name)
+		if err != nil {
+			t.Fatalf("git show エラー: %v", err)
+		}
+		if actual != content {
+			t.Errorf("%s が正規化されていません: %q", name, actual)
+		}
+	}
+
+	stdout, _, err := utils.RunCommand(repo, "git", "ls-tree", "HEAD")
+	if err != nil {
+		t.Fatalf("git ls-tree エラー: %v", err)
+	}
+	if !containsLine(stdout, "100755", "script.sh") {
+		t.Errorf("シバンで始まるファイルの実行権限が外されました:\n%s", stdout)
+	}
+	if !containsLine(stdout, "100644", "data.txt") || !containsLine(stdout, "100644", "tool ") {
+		t.Errorf("不要な実行権限が外されていません:\n%s", stdout)
+	}
+
+	// 一時的に設定したattributesは削除される
+	if utils.FileExists(filepath.Join(repo, ".git", "info", "attributes")) {
+		t.Error(".git/info/attributes が残っています")
+	}
+}
+
+// TestRewriteHistoryWithoutNormalization は正規化を指定しない場合にツリーが変わらないことをテストする
+func TestRewriteHistoryWithoutNormalization(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, "crlf.txt"), []byte("line \r\n"), 0644); err != nil {
+		t.Fatalf("ファイル作成エラー: %v", err)
+	}
+	for _, args := range [][]string{{"git", "add", "."}, {"git", "commit", "-m", "crlf"}} {
+		if _, stderr, err := utils.RunCommand(repo, args[0], args[1:]...); err != nil {
+			t.Fatalf("%v エラー: %v, stderr: %s", args, err, stderr)
+		}
+	}
+	treeBefore, _, _ := utils.RunCommand(repo, "git", "rev-parse", "HEAD^{tree}")
+
+	if err := RewriteHistory(repo, "newuser", "new@example.com"); err != nil {
+		t.Fatalf("RewriteHistoryでエラーが発生しました: %v", err)
+	}
+
+	treeAfter, _, _ := utils.RunCommand(repo, "git", "rev-parse", "HEAD^{tree}")
+	if treeBefore != treeAfter {
+		t.Error("正規化を指定していないのにツリーが変更されました")
+	}
+}
+
+// TestTreeFilterWithoutTrailingWhitespace は行末の空白を含むファイルがない場合にperlを実行しないことをテストする
+func TestTreeFilterWithoutTrailingWhitespace(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	normalizer := &treeNormalizer{normalization: TreeNormalization{TrailingWhitespace: []string{"*.txt"}}}
+
+	// 引数なしでperlが実行されると標準入力を書き換えて出力する
+	cmd := exec.Command("sh", "-c", normalizer.treeFilter())
+	cmd.Dir = repo
+	cmd.Stdin = strings.NewReader("stdin   \n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("tree-filterの実行でエラーが発生しました: %v\n%s", err, output)
+	}
+	if len(output) != 0 {
+		t.Errorf("一致するファイルがないのにperlが実行されました: %q", output)
+	}
+}
+
+// TestWhitespacePathspec はglobパターンからgit grepのpathspecへの変換をテストする
+func TestWhitespacePathspec(t *testing.T) {
+	tests := map[string]string{
+		"*.go":       ":(glob)**/*.go",
+		"/README.md": ":(glob)README.md",
+		"docs/*.md":  ":(glob)docs/*.md",
+		"src/**/*.c": ":(glob)src/**/*.c",
+	}
+	for pattern, expected := range tests {
+		if actual := whitespacePathspec(pattern); actual != expected {
+			t.Errorf("%s: 期待値 %s, 実際 %s", pattern, expected, actual)
+		}
+	}
+}
+
+// containsLine はls-treeの出力に指定したモードとファイル名の行が含まれているかを返す
+func containsLine(output, mode, name string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, mode+" ") && strings.HasSuffix(line, "\t"+name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"git-rewrite/pkg/utils"
+)
+
+// DefaultOldRemoteName は移行前のリモートを残す際のリモート名（{remote}は元のリモート名に置き換える）
+const DefaultOldRemoteName = "old-{remote}"
+
+// migrationConfigSection は移行の記録を保存するgit configのセクション
+// 例: git-rewrite.origin.oldurl, git-rewrite.origin.backupremote, git-rewrite.origin.renamedurl
+const migrationConfigSection = "git-rewrite"
+
+// OldRemoteName は移行前のリモートを残す際のリモート名を返す
+func OldRemoteName(template, remote string) string {
+	return strings.ReplaceAll(template, "{remote}", remote)
+}
+
+// RemoteURLs はリモート名とURLの対応を返す
+func RemoteURLs(gitDir string) (map[string]string, error) {
+	remotes, err := ListRemotes(gitDir)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make(map[string]string)
+	for _, remote := range remotes {
+		stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", remote)
+		if err != nil {
+			continue
+		}
+		urls[remote] = strings.TrimSpace(stdout)
+	}
+	return urls, nil
+}
+
+// KeepOldRemote は移行前のURLをbackupRemoteとして残し、移行の記録をgit configに保存する
+// backupRemoteが既に存在する場合は、最初の移行前のURLを残すため変更しない
+func KeepOldRemote(gitDir, remote, backupRemote, oldURL, newURL string) error {
+	if backupRemote == remote {
+		return fmt.Errorf("移行前のリモート名が元のリモート名と同じです: %s", remote)
+	}
+
+	stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", backupRemote)
+	if err != nil {
+		if _, stderr, err := utils.RunCommand(gitDir, "git", "remote", "add", backupRemote, oldURL); err != nil {
+			return fmt.Errorf("移行前のリモート追加エラー: %v\nstderr: %s", err, stderr)
+		}
+		fmt.Printf("移行前のURLをremote %s として残しました: %s\n", backupRemote, oldURL)
+	} else if existing := strings.TrimSpace(stdout); existing != oldURL {
+		fmt.Printf("⚠️  remote %s は既に存在するため変更しません: %s\n", backupRemote, existing)
+	}
+
+	record := [][2]string{
+		{"oldurl", oldURL},
+		{"newurl", newURL},
+		{"backupremote", backupRemote},
+		{"migratedat", time.Now().Format(time.RFC3339)},
+	}
+	for _, entry := range record {
+		key := fmt.Sprintf("%s.%s.%s", migrationConfigSection, remote, entry[0])
+		if _, stderr, err := utils.RunCommand(gitDir, "git", "config", key, entry[1]); err != nil {
+			return fmt.Errorf("移行の記録エラー: %v\nstderr: %s", err, stderr)
+		}
+	}
+	return nil
+}
+
+// renamedRemoteURL はリポジトリ名のルールを適用して設定したリモートURLの記録を返す（記録がない場合は空）
+func renamedRemoteURL(gitDir, remote string) string {
+	key := fmt.Sprintf("%s.%s.renamedurl", migrationConfigSection, remote)
+	stdout, _, err := utils.RunCommand(gitDir, "git", "config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(stdout)
+}
+
+// recordRenamedRemoteURL はリポジトリ名のルールを適用して設定したリモートURLを記録する
+func recordRenamedRemoteURL(gitDir, remote, remoteURL string) error {
+	key := fmt.Sprintf("%s.%s.renamedurl", migrationConfigSection, remote)
+	if _, stderr, err := utils.RunCommand(gitDir, "git", "config", key, remoteURL); err != nil {
+		return fmt.Errorf("リポジトリ名の変更の記録エラー: %v\nstderr: %s", err, stderr)
+	}
+	return nil
+}
+
+// IsOldRemote はKeepOldRemoteで残した移行前のリモートかどうかを返す
+func IsOldRemote(gitDir, remote string) bool {
+	pattern := fmt.Sprintf(`^%s\..*\.backupremote$`, migrationConfigSection)
+	stdout, _, err := utils.RunCommand(gitDir, "git", "config", "--get-regexp", pattern)
+	if err != nil {
+		// 一致するキーがない場合も終了コード1になる
+		return false
+	}
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[1] == remote {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// TestKeepOldRemote は移行前のURLがリモートとして残り、移行の記録が保存されることをテストする
+func TestKeepOldRemote(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", "origin", "https://github.com/neworg/app"); err != nil {
+		t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+	}
+
+	if err := KeepOldRemote(repo, "origin", OldRemoteName(DefaultOldRemoteName, "origin"), "https://github.com/olduser/app", "https://github.com/neworg/app"); err != nil {
+		t.Fatalf("KeepOldRemoteでエラーが発生しました: %v", err)
+	}
+	if url := remoteURLs(t, repo)["old-origin"]; url != "https://github.com/olduser/app" {
+		t.Errorf("移行前のURLがold-originとして残っていません: %s", url)
+	}
+	for key, expected := range map[string]string{
+		"git-rewrite.origin.oldurl":       "https://github.com/olduser/app",
+		"git-rewrite.origin.newurl":       "https://github.com/neworg/app",
+		"git-rewrite.origin.backupremote": "old-origin",
+	} {
+		actual, _, err := utils.RunCommand(repo, "git", "config", key)
+		if err != nil || strings.TrimSpace(actual) != expected {
+			t.Errorf("%s: 期待値 %s, 実際 %s (%v)", key, expected, strings.TrimSpace(actual), err)
+		}
+	}
+	if _, _, err := utils.RunCommand(repo, "git", "config", "git-rewrite.origin.migratedat"); err != nil {
+		t.Errorf("移行日時が記録されていません: %v", err)
+	}
+
+	// 2回目の移行では最初の移行前のURLを維持する
+	if err := KeepOldRemote(repo, "origin", "old-origin", "https://github.com/neworg/app", "https://github.com/otherorg/app"); err != nil {
+		t.Fatalf("KeepOldRemoteでエラーが発生しました: %v", err)
+	}
+	if url := remoteURLs(t, repo)["old-origin"]; url != "https://github.com/olduser/app" {
+		t.Errorf("既存のold-originが変更されました: %s", url)
+	}
+
+	if !IsOldRemote(repo, "old-origin") || IsOldRemote(repo, "origin") {
+		t.Error("移行前のリモートの判定が正しくありません")
+	}
+
+	if err := KeepOldRemote(repo, "origin", "origin", "a", "b"); err == nil {
+		t.Error("元のリモートと同じ名前でエラーが期待されました")
+	}
+}
+
+// TestUpdateAllRemoteURLsSkipsOldRemote は --all-remotes が移行前のリモートを変更しないことをテストする
+func TestUpdateAllRemoteURLsSkipsOldRemote(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+
+	repo := setupPreflightRepo(t)
+	if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", "origin", "https://github.com/neworg/app"); err != nil {
+		t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+	}
+	if err := KeepOldRemote(repo, "origin", "old-origin", "https://github.com/olduser/app", "https://github.com/neworg/app"); err != nil {
+		t.Fatalf("KeepOldRemoteでエラーが発生しました: %v", err)
+	}
+
+	if err := UpdateAllRemoteURLs(repo, DefaultRemoteHosts(), "newuser", "", "neworg", nil); err != nil {
+		t.Fatalf("UpdateAllRemoteURLsでエラーが発生しました: %v", err)
+	}
+	if url := remoteURLs(t, repo)["old-origin"]; url != "https://github.com/olduser/app" {
+		t.Errorf("移行前のリモートが変更されました: %s", url)
+	}
+}
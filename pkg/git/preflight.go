@@ -0,0 +1,211 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"git-rewrite/pkg/utils"
+)
+
+// 作業ツリーが汚れている場合の処理方針
+const (
+	DirtyPolicyRefuse   = "refuse"   // 処理を中止する（デフォルト）
+	DirtyPolicyStash    = "stash"    // 自動でstashし、処理後に復元する
+	DirtyPolicyContinue = "continue" // 警告のみ表示して処理を続行する（追跡中ファイルの変更がある場合は中止する）
+)
+
+// autoStashMessage は自動stash時に付与するメッセージ
+const autoStashMessage = "git-rewrite: auto-stash before history rewrite"
+
+// PreflightReport は事前チェックの結果を表す
+type PreflightReport struct {
+	Dirty        bool   // 追跡中ファイルに未コミットの変更がある
+	Untracked    bool   // 未追跡ファイルがある
+	NoCommits    bool   // コミットが1つもない（初回コミットで未追跡ファイルも追加される）
+	StashCount   int    // 既存のstashエントリ数
+	InProgress   string // 進行中の操作（rebase, merge, cherry-pick, revert）
+	Action       string // 実施した対応（refuse, stash, continue, clean）
+	Stashed      bool   // 自動stashを実行したかどうか
+	Restored     bool   // 自動stashを復元したかどうか
+	RestoreError error  // 自動stash復元時のエラー
+	StashWarning string // 既存stashに関する注意事項
+}
+
+// IsClean は作業ツリーに問題がないかどうかを返す
+func (p *PreflightReport) IsClean() bool {
+	return !p.Dirty && !p.Untracked && p.InProgress == ""
+}
+
+// IsValidDirtyPolicy は処理方針の妥当性をチェックする
+func IsValidDirtyPolicy(policy string) bool {
+	switch policy {
+	case DirtyPolicyRefuse, DirtyPolicyStash, DirtyPolicyContinue:
+		return true
+	}
+	return false
+}
+
+// InspectWorkTree は作業ツリーとリポジトリの状態を調べる
+func InspectWorkTree(gitDir string) (*PreflightReport, error) {
+	gitPath := filepath.Join(gitDir, ".git")
+	if !utils.FileExists(gitPath) {
+		return nil, fmt.Errorf("エラー: %s はGitリポジトリではありません", gitDir)
+	}
+
+	report := &PreflightReport{}
+
+	// 進行中の操作を検出
+	inProgressMarkers := []struct {
+		path      string
+		operation string
+	}{
+		{"rebase-merge", "rebase"},
+		{"rebase-apply", "rebase"},
+		{"MERGE_HEAD", "merge"},
+		{"CHERRY_PICK_HEAD", "cherry-pick"},
+		{"REVERT_HEAD", "revert"},
+	}
+	for _, marker := range inProgressMarkers {
+		if utils.FileExists(filepath.Join(gitPath, marker.path)) {
+			report.InProgress = marker.operation
+			break
+		}
+	}
+
+	// 作業ツリーの変更を検出
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "status", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("git status エラー: %v\nstderr: %s", err, stderr)
+	}
+	for _, line := range strings.Split(stdout, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "??") {
+			report.Untracked = true
+		} else {
+			report.Dirty = true
+		}
+	}
+
+	// コミットの有無を検出
+	if _, _, err := utils.RunCommand(gitDir, "git", "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		report.NoCommits = true
+	}
+
+	// 既存のstashを検出
+	stdout, _, err = utils.RunCommand(gitDir, "git", "stash", "list")
+	if err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+			if line != "" {
+				report.StashCount++
+			}
+		}
+	}
+	if report.StashCount > 0 {
+		report.StashWarning = fmt.Sprintf("既存のstashが %d 件あります。stashは書き換えられないため、旧author/emailが残ります", report.StashCount)
+	}
+
+	return report, nil
+}
+
+// RunPreflight は処理方針に従って事前チェックを行う
+// 自動stashを行った場合は、処理後にRestorePreflightを呼び出す必要がある
+func RunPreflight(gitDir, policy string) (*PreflightReport, error) {
+	if policy == "" {
+		policy = DirtyPolicyRefuse
+	}
+	if !IsValidDirtyPolicy(policy) {
+		return nil, fmt.Errorf("無効な処理方針です: %s (refuse, stash, continue のいずれかを指定してください)", policy)
+	}
+
+	fmt.Println("[0/2] 作業ツリーの状態を確認しています...")
+
+	report, err := InspectWorkTree(gitDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.StashWarning != "" {
+		fmt.Printf("⚠️  %s\n", report.StashWarning)
+	}
+
+	if report.IsClean() {
+		report.Action = "clean"
+		fmt.Println("✅ 作業ツリーはクリーンです。")
+		return report, nil
+	}
+
+	if report.InProgress != "" {
+		fmt.Printf("⚠️  %s が進行中です。\n", report.InProgress)
+	}
+	if report.Dirty {
+		fmt.Println("⚠️  コミットされていない変更があります。")
+	}
+	if report.Untracked {
+		fmt.Println("⚠️  未追跡のファイルがあります。")
+	}
+
+	switch policy {
+	case DirtyPolicyContinue:
+		// git filter-branchは追跡中ファイルに未コミットの変更があると履歴を書き換えられないため中止する
+		if report.Dirty {
+			report.Action = DirtyPolicyRefuse
+			return report, fmt.Errorf("コミットされていない変更があるため処理を中止しました（continueで続行できるのは未追跡ファイル・既存のstashのみです。--dirty-policy stash を指定するか、変更をコミットしてください）")
+		}
+		report.Action = DirtyPolicyContinue
+		fmt.Println("処理方針がcontinueのため、このまま続行します。")
+		return report, nil
+
+	case DirtyPolicyStash:
+		// 進行中の操作はstashで退避できないため中止する
+		if report.InProgress != "" {
+			report.Action = DirtyPolicyRefuse
+			return report, fmt.Errorf("%s が進行中のため自動stashできません。操作を完了または中止してから再実行してください", report.InProgress)
+		}
+		if _, stderr, err := utils.RunCommand(gitDir, "git", "stash", "push", "--include-untracked", "-m", autoStashMessage); err != nil {
+			report.Action = DirtyPolicyRefuse
+			return report, fmt.Errorf("自動stashに失敗しました: %v\nstderr: %s", err, stderr)
+		}
+		report.Action = DirtyPolicyStash
+		report.Stashed = true
+		fmt.Println("✅ 未コミットの変更を一時的にstashしました。")
+		return report, nil
+
+	default:
+		report.Action = DirtyPolicyRefuse
+		if report.InProgress != "" {
+			return report, fmt.Errorf("%s が進行中のため処理を中止しました", report.InProgress)
+		}
+		if report.Dirty {
+			return report, fmt.Errorf("コミットされていない変更があるため処理を中止しました（--dirty-policy stash または continue で変更できます）")
+		}
+		// コミットがない場合は初回コミットで未追跡ファイルもコミット・プッシュされるため中止する
+		if report.NoCommits {
+			return report, fmt.Errorf("コミットがなく未追跡のファイルがあるため処理を中止しました（初回コミットに含まれます。不要なファイルを削除するか .gitignore に追加してください）")
+		}
+		// 未追跡ファイルのみの場合は履歴書き換えに影響しないため続行する
+		report.Action = DirtyPolicyContinue
+		fmt.Println("未追跡のファイルのみのため、このまま続行します。")
+		return report, nil
+	}
+}
+
+// RestorePreflight は自動stashした変更を復元する
+func RestorePreflight(gitDir string, report *PreflightReport) error {
+	if report == nil || !report.Stashed || report.Restored {
+		return nil
+	}
+
+	fmt.Println("stashした変更を復元しています...")
+	if _, stderr, err := utils.RunCommand(gitDir, "git", "stash", "pop", "--index"); err != nil {
+		report.RestoreError = fmt.Errorf("stashの復元に失敗しました（git stash list で確認してください）: %v\nstderr: %s", err, stderr)
+		fmt.Printf("⚠️  %v\n", report.RestoreError)
+		return report.RestoreError
+	}
+
+	report.Restored = true
+	fmt.Println("✅ stashした変更を復元しました。")
+	return nil
+}
@@ -0,0 +1,270 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// setupPreflightRepo はコミット済みのテスト用リポジトリを作成する
+func setupPreflightRepo(t *testing.T) string {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	commands := [][]string{
+		{"git", "init"},
+		{"git", "config", "user.name", "Test User"},
+		{"git", "config", "user.email", "test@example.com"},
+	}
+	for _, args := range commands {
+		if _, stderr, err := utils.RunCommand(tempDir, args[0], args[1:]...); err != nil {
+			t.Fatalf("%v エラー: %v, stderr: %s", args, err, stderr)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("initial\n"), 0644); err != nil {
+		t.Fatalf("ファイル作成エラー: %v", err)
+	}
+	for _, args := range [][]string{{"git", "add", "file.txt"}, {"git", "commit", "-m", "initial"}} {
+		if _, stderr, err := utils.RunCommand(tempDir, args[0], args[1:]...); err != nil {
+			t.Fatalf("%v エラー: %v, stderr: %s", args, err, stderr)
+		}
+	}
+
+	return tempDir
+}
+
+// TestInspectWorkTree は作業ツリーの状態検出をテストする
+func TestInspectWorkTree(t *testing.T) {
+	t.Run("クリーンな作業ツリー", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		report, err := InspectWorkTree(repo)
+		if err != nil {
+			t.Fatalf("InspectWorkTreeでエラーが発生しました: %v", err)
+		}
+		if !report.IsClean() {
+			t.Errorf("クリーンな作業ツリーが汚れていると判定されました: %+v", report)
+		}
+	})
+
+	t.Run("未コミットの変更", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "file.txt"), []byte("changed\n"), 0644)
+
+		report, err := InspectWorkTree(repo)
+		if err != nil {
+			t.Fatalf("InspectWorkTreeでエラーが発生しました: %v", err)
+		}
+		if !report.Dirty {
+			t.Error("未コミットの変更が検出されませんでした")
+		}
+	})
+
+	t.Run("進行中のマージ", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, ".git", "MERGE_HEAD"), []byte("0000000000000000000000000000000000000000\n"), 0644)
+
+		report, err := InspectWorkTree(repo)
+		if err != nil {
+			t.Fatalf("InspectWorkTreeでエラーが発生しました: %v", err)
+		}
+		if report.InProgress != "merge" {
+			t.Errorf("期待される進行中の操作: merge, 実際: %s", report.InProgress)
+		}
+	})
+
+	t.Run("既存のstash", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "file.txt"), []byte("stashed\n"), 0644)
+		utils.RunCommand(repo, "git", "stash")
+
+		report, err := InspectWorkTree(repo)
+		if err != nil {
+			t.Fatalf("InspectWorkTreeでエラーが発生しました: %v", err)
+		}
+		if report.StashCount != 1 || report.StashWarning == "" {
+			t.Errorf("既存のstashが検出されませんでした: %+v", report)
+		}
+	})
+
+	t.Run("Gitリポジトリでないディレクトリ", func(t *testing.T) {
+		if _, err := InspectWorkTree(t.TempDir()); err == nil {
+			t.Error("Gitリポジトリでないディレクトリでエラーが期待されました")
+		}
+	})
+}
+
+// TestRunPreflight は処理方針ごとの事前チェックをテストする
+func TestRunPreflight(t *testing.T) {
+	t.Run("refuseで未コミットの変更", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "file.txt"), []byte("changed\n"), 0644)
+
+		report, err := RunPreflight(repo, DirtyPolicyRefuse)
+		if err == nil {
+			t.Error("refuseで未コミットの変更がある場合はエラーが期待されました")
+		}
+		if report == nil || report.Action != DirtyPolicyRefuse {
+			t.Errorf("期待されるAction: refuse, 実際: %+v", report)
+		}
+	})
+
+	t.Run("refuseで未追跡ファイルのみ", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "untracked.txt"), []byte("new\n"), 0644)
+
+		if _, err := RunPreflight(repo, DirtyPolicyRefuse); err != nil {
+			t.Errorf("未追跡ファイルのみの場合はエラーが期待されませんでした: %v", err)
+		}
+	})
+
+	t.Run("refuseでコミットのないリポジトリの未追跡ファイル", func(t *testing.T) {
+		repo := t.TempDir()
+		if _, stderr, err := utils.RunCommand(repo, "git", "init"); err != nil {
+			t.Fatalf("git init エラー: %v, stderr: %s", err, stderr)
+		}
+		os.WriteFile(filepath.Join(repo, "secret.env"), []byte("TOKEN=xxx\n"), 0644)
+
+		report, err := RunPreflight(repo, DirtyPolicyRefuse)
+		if err == nil {
+			t.Error("初回コミットに含まれる未追跡ファイルがある場合はエラーが期待されました")
+		}
+		if report == nil || !report.NoCommits || report.Action != DirtyPolicyRefuse {
+			t.Errorf("期待されるAction: refuse, 実際: %+v", report)
+		}
+	})
+
+	t.Run("continueで進行中の操作", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.MkdirAll(filepath.Join(repo, ".git", "rebase-merge"), 0755)
+
+		report, err := RunPreflight(repo, DirtyPolicyContinue)
+		if err != nil {
+			t.Errorf("continueではエラーが期待されませんでした: %v", err)
+		}
+		if report.Action != DirtyPolicyContinue {
+			t.Errorf("期待されるAction: continue, 実際: %s", report.Action)
+		}
+	})
+
+	t.Run("continueで未コミットの変更", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "file.txt"), []byte("changed\n"), 0644)
+
+		report, err := RunPreflight(repo, DirtyPolicyContinue)
+		if err == nil {
+			t.Error("continueで追跡中ファイルの変更がある場合はエラーが期待されました")
+		}
+		if report == nil || report.Action != DirtyPolicyRefuse {
+			t.Errorf("期待されるAction: refuse, 実際: %+v", report)
+		}
+	})
+
+	t.Run("stashで退避と復元", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "file.txt"), []byte("changed\n"), 0644)
+
+		report, err := RunPreflight(repo, DirtyPolicyStash)
+		if err != nil {
+			t.Fatalf("stashでエラーが発生しました: %v", err)
+		}
+		if !report.Stashed {
+			t.Fatal("自動stashが実行されていません")
+		}
+
+		content, _ := os.ReadFile(filepath.Join(repo, "file.txt"))
+		if string(content) != "initial\n" {
+			t.Errorf("stash後の作業ツリーが元に戻っていません: %q", string(content))
+		}
+
+		if err := RestorePreflight(repo, report); err != nil {
+			t.Fatalf("stashの復元でエラーが発生しました: %v", err)
+		}
+		content, _ = os.ReadFile(filepath.Join(repo, "file.txt"))
+		if string(content) != "changed\n" {
+			t.Errorf("stashした変更が復元されていません: %q", string(content))
+		}
+		if !report.Restored {
+			t.Error("Restoredがtrueになっていません")
+		}
+	})
+
+	t.Run("stashで進行中の操作", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, ".git", "CHERRY_PICK_HEAD"), []byte("0000000000000000000000000000000000000000\n"), 0644)
+
+		_, err := RunPreflight(repo, DirtyPolicyStash)
+		if err == nil || !strings.Contains(err.Error(), "cherry-pick") {
+			t.Errorf("進行中のcherry-pickでエラーが期待されました: %v", err)
+		}
+	})
+
+	t.Run("無効な処理方針", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		if _, err := RunPreflight(repo, "invalid"); err == nil {
+			t.Error("無効な処理方針でエラーが期待されました")
+		}
+	})
+}
+
+// TestRunPreflightThenRewriteHistory は事前チェックで続行した場合に履歴の書き換えまで成功することをテストする
+func TestRunPreflightThenRewriteHistory(t *testing.T) {
+	t.Run("continueで未追跡ファイルと既存のstash", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "file.txt"), []byte("stashed\n"), 0644)
+		if _, stderr, err := utils.RunCommand(repo, "git", "stash"); err != nil {
+			t.Fatalf("git stash エラー: %v, stderr: %s", err, stderr)
+		}
+		os.WriteFile(filepath.Join(repo, "untracked.txt"), []byte("new\n"), 0644)
+
+		report, err := RunPreflight(repo, DirtyPolicyContinue)
+		if err != nil {
+			t.Fatalf("continueでエラーが発生しました: %v", err)
+		}
+		if report.Action != DirtyPolicyContinue {
+			t.Errorf("期待されるAction: continue, 実際: %s", report.Action)
+		}
+		if err := RewriteHistory(repo, "newuser", "new@example.com"); err != nil {
+			t.Fatalf("履歴の書き換えでエラーが発生しました: %v", err)
+		}
+		if emails := authorEmails(t, repo, "HEAD"); emails["initial"] != "new@example.com" {
+			t.Errorf("履歴が書き換えられていません: %v", emails)
+		}
+	})
+
+	t.Run("continueで未コミットの変更", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "file.txt"), []byte("changed\n"), 0644)
+
+		if _, err := RunPreflight(repo, DirtyPolicyContinue); err == nil {
+			t.Fatal("continueで追跡中ファイルの変更がある場合はエラーが期待されました")
+		}
+		content, _ := os.ReadFile(filepath.Join(repo, "file.txt"))
+		if string(content) != "changed\n" {
+			t.Errorf("中止した場合に作業ツリーが変更されています: %q", string(content))
+		}
+	})
+
+	t.Run("stashで未コミットの変更", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "file.txt"), []byte("changed\n"), 0644)
+
+		report, err := RunPreflight(repo, DirtyPolicyStash)
+		if err != nil {
+			t.Fatalf("stashでエラーが発生しました: %v", err)
+		}
+		if err := RewriteHistory(repo, "newuser", "new@example.com"); err != nil {
+			t.Fatalf("履歴の書き換えでエラーが発生しました: %v", err)
+		}
+		if err := RestorePreflight(repo, report); err != nil {
+			t.Fatalf("stashの復元でエラーが発生しました: %v", err)
+		}
+		content, _ := os.ReadFile(filepath.Join(repo, "file.txt"))
+		if string(content) != "changed\n" {
+			t.Errorf("stashした変更が復元されていません: %q", string(content))
+		}
+	})
+}
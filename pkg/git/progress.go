@@ -0,0 +1,213 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git-rewrite/pkg/utils"
+)
+
+// filterBranchProgressRegex はfilter-branchの進捗行（Rewrite <sha> (N/M)）に一致する
+var filterBranchProgressRegex = regexp.MustCompile(`Rewrite [0-9a-f]+ \((\d+)/(\d+)\)`)
+
+// 進捗表示の間隔
+const (
+	ttyProgressInterval    = 200 * time.Millisecond // TTYでの再描画間隔
+	nonTTYProgressInterval = 30 * time.Second       // 非TTYでの最大出力間隔
+	nonTTYProgressStep     = 10                     // 非TTYで出力する進捗率の刻み（%）
+)
+
+// ParseFilterBranchProgress はfilter-branchの出力行から処理済みコミット数と総数を取得する
+func ParseFilterBranchProgress(line string) (int, int, bool) {
+	matches := filterBranchProgressRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return 0, 0, false
+	}
+	done, err1 := strconv.Atoi(matches[1])
+	total, err2 := strconv.Atoi(matches[2])
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return done, total, true
+}
+
+// CountCommits は書き換え対象となるコミット数を数える
+func CountCommits(gitDir string) (int, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "rev-list", "--count", "--branches", "--tags")
+	if err != nil {
+		return 0, fmt.Errorf("コミット数取得エラー: %v\nstderr: %s", err, stderr)
+	}
+	return strconv.Atoi(strings.TrimSpace(stdout))
+}
+
+// BatchProgress は複数リポジトリにまたがる全体の進捗を表す
+type BatchProgress struct {
+	TotalRepos   int
+	TotalCommits int
+
+	mu          sync.Mutex
+	doneRepos   int
+	doneCommits int
+	startedAt   time.Time
+}
+
+// NewBatchProgress は全体の進捗を作成する
+func NewBatchProgress(totalRepos, totalCommits int) *BatchProgress {
+	return &BatchProgress{
+		TotalRepos:   totalRepos,
+		TotalCommits: totalCommits,
+		startedAt:    time.Now(),
+	}
+}
+
+// CompleteRepository はリポジトリ1件分の処理完了を記録する
+func (b *BatchProgress) CompleteRepository(commits int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.doneRepos++
+	b.doneCommits += commits
+}
+
+// snapshot は処理中のコミット数を加味した全体の進捗を返す
+func (b *BatchProgress) snapshot(inFlight int) (int, int, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.doneRepos, b.doneCommits + inFlight, time.Since(b.startedAt)
+}
+
+// ProgressReporter はリポジトリ単位の進捗を表示する
+type ProgressReporter struct {
+	out       io.Writer
+	isTTY     bool
+	batch     *BatchProgress
+	startedAt time.Time
+
+	done        int
+	total       int
+	lastPrinted time.Time
+	lastStep    int
+	printedLine bool
+}
+
+// NewProgressReporter は標準出力に進捗を表示するProgressReporterを作成する
+func NewProgressReporter(batch *BatchProgress) *ProgressReporter {
+	return newProgressReporter(os.Stdout, isTerminal(os.Stdout), batch)
+}
+
+func newProgressReporter(out io.Writer, isTTY bool, batch *BatchProgress) *ProgressReporter {
+	return &ProgressReporter{
+		out:       out,
+		isTTY:     isTTY,
+		batch:     batch,
+		startedAt: time.Now(),
+		lastStep:  -1,
+	}
+}
+
+// Update は処理済みコミット数を更新し、必要に応じて進捗を表示する
+func (p *ProgressReporter) Update(done, total int) {
+	p.done, p.total = done, total
+	now := time.Now()
+
+	if p.isTTY {
+		if now.Sub(p.lastPrinted) < ttyProgressInterval && done < total {
+			return
+		}
+		fmt.Fprintf(p.out, "\r%s", p.format(now))
+		p.printedLine = true
+		p.lastPrinted = now
+		return
+	}
+
+	// 非TTYではログが溢れないよう、進捗率の刻みまたは一定時間ごとにのみ出力する
+	step := 0
+	if total > 0 {
+		step = done * 100 / total / nonTTYProgressStep
+	}
+	if step == p.lastStep && now.Sub(p.lastPrinted) < nonTTYProgressInterval {
+		return
+	}
+	fmt.Fprintln(p.out, p.format(now))
+	p.lastStep = step
+	p.lastPrinted = now
+}
+
+// Finish は進捗表示を終了する
+func (p *ProgressReporter) Finish() {
+	if p.isTTY && p.printedLine {
+		fmt.Fprintln(p.out)
+	}
+}
+
+// format は進捗行を生成する
+func (p *ProgressReporter) format(now time.Time) string {
+	line := fmt.Sprintf("  進捗: %s", formatProgress(p.done, p.total, now.Sub(p.startedAt)))
+	if p.batch != nil && p.batch.TotalCommits > 0 {
+		doneRepos, doneCommits, elapsed := p.batch.snapshot(p.done)
+		line += fmt.Sprintf(" | 全体 [%d/%d リポジトリ]: %s", doneRepos, p.batch.TotalRepos,
+			formatProgress(doneCommits, p.batch.TotalCommits, elapsed))
+	}
+	return line
+}
+
+// formatProgress は処理済み数・速度・残り時間を文字列にする
+func formatProgress(done, total int, elapsed time.Duration) string {
+	percent := 0.0
+	if total > 0 {
+		percent = float64(done) * 100 / float64(total)
+	}
+	text := fmt.Sprintf("%d/%d コミット (%.1f%%)", done, total, percent)
+
+	if elapsed <= 0 || done == 0 {
+		return text
+	}
+	rate := float64(done) / elapsed.Seconds()
+	text += fmt.Sprintf(" %.1f コミット/秒", rate)
+	if done < total && rate > 0 {
+		remaining := time.Duration(float64(total-done) / rate * float64(time.Second))
+		text += fmt.Sprintf(" 残り約 %s", remaining.Round(time.Second))
+	}
+	return text
+}
+
+// progressWriter はコマンドの出力を保持しつつ、進捗行を解析してProgressReporterに渡す
+type progressWriter struct {
+	reporter *ProgressReporter
+	output   bytes.Buffer
+	pending  []byte
+}
+
+// Write はio.Writerを実装する
+func (w *progressWriter) Write(data []byte) (int, error) {
+	w.output.Write(data)
+	w.pending = append(w.pending, data...)
+
+	// filter-branchは進捗を \r 区切りで出力するため、\r と \n の両方で行を区切る
+	for {
+		index := bytes.IndexAny(w.pending, "\r\n")
+		if index < 0 {
+			break
+		}
+		if done, total, ok := ParseFilterBranchProgress(string(w.pending[:index])); ok {
+			w.reporter.Update(done, total)
+		}
+		w.pending = w.pending[index+1:]
+	}
+	return len(data), nil
+}
+
+// isTerminal は出力先が端末かどうかを判定する
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
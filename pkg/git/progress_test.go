@@ -0,0 +1,86 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseFilterBranchProgress はfilter-branchの進捗行の解析をテストする
+func TestParseFilterBranchProgress(t *testing.T) {
+	tests := []struct {
+		line  string
+		done  int
+		total int
+		ok    bool
+	}{
+		{"Rewrite 3f2a1b4c5d6e7f8091a2b3c4d5e6f708192a3b4c (12/345)", 12, 345, true},
+		{"Rewrite 3f2a1b4c (345/345) (3 seconds passed, remaining 0 predicted)    ", 345, 345, true},
+		{"Ref 'refs/heads/main' was rewritten", 0, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		done, total, ok := ParseFilterBranchProgress(tt.line)
+		if done != tt.done || total != tt.total || ok != tt.ok {
+			t.Errorf("ParseFilterBranchProgress(%q): 期待値 (%d, %d, %t), 実際 (%d, %d, %t)",
+				tt.line, tt.done, tt.total, tt.ok, done, total, ok)
+		}
+	}
+}
+
+// TestFormatProgress は速度と残り時間の表示をテストする
+func TestFormatProgress(t *testing.T) {
+	text := formatProgress(50, 200, 10*time.Second)
+	for _, expected := range []string{"50/200 コミット", "25.0%", "5.0 コミット/秒", "残り約 30s"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("進捗表示に %q が含まれていません: %s", expected, text)
+		}
+	}
+
+	// 完了時は残り時間を表示しない
+	if text := formatProgress(200, 200, 10*time.Second); strings.Contains(text, "残り") {
+		t.Errorf("完了時に残り時間が表示されました: %s", text)
+	}
+}
+
+// TestProgressWriterNonTTY は非TTYでの出力頻度をテストする
+func TestProgressWriterNonTTY(t *testing.T) {
+	var out bytes.Buffer
+	reporter := newProgressReporter(&out, false, nil)
+	writer := &progressWriter{reporter: reporter}
+
+	// 1000コミット分の進捗を \r 区切りで書き込む
+	for i := 1; i <= 1000; i++ {
+		writer.Write([]byte(fmt.Sprintf("\rRewrite abcdef0 (%d/1000)    ", i)))
+	}
+	writer.Write([]byte("\n\nRef 'refs/heads/main' was rewritten\n"))
+	reporter.Finish()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) > 12 {
+		t.Errorf("非TTYで進捗が出力されすぎています: %d 行", len(lines))
+	}
+	if !strings.Contains(lines[len(lines)-1], "1000/1000") {
+		t.Errorf("最終行に完了の進捗が含まれていません: %s", lines[len(lines)-1])
+	}
+	if !strings.Contains(writer.output.String(), "was rewritten") {
+		t.Error("コマンドの出力が保持されていません")
+	}
+}
+
+// TestProgressReporterBatch は全体の進捗表示をテストする
+func TestProgressReporterBatch(t *testing.T) {
+	batch := NewBatchProgress(2, 300)
+	batch.CompleteRepository(100)
+
+	var out bytes.Buffer
+	reporter := newProgressReporter(&out, false, batch)
+	reporter.Update(100, 200)
+
+	if !strings.Contains(out.String(), "全体 [1/2 リポジトリ]: 200/300 コミット") {
+		t.Errorf("全体の進捗が正しく表示されていません: %s", out.String())
+	}
+}
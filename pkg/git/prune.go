@@ -0,0 +1,145 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"git-rewrite/pkg/utils"
+)
+
+// PruneReport は書き換え後のクリーンアップ結果を表す
+type PruneReport struct {
+	SizeBefore         int64  // クリーンアップ前の.gitディレクトリのサイズ（バイト）
+	SizeAfter          int64  // クリーンアップ後の.gitディレクトリのサイズ（バイト）
+	BackupPath         string // 作成したバックアップbundleのパス
+	BackupRefsRemoved  bool   // refs/originalを削除したかどうか
+	ExpiredReflogCount int    // reflogを削除した参照の数
+	StashKept          bool   // stashが残っているため、stashから参照される書き換え前のオブジェクトを削除できなかったかどうか
+}
+
+// PruneRepository は書き換え前のオブジェクトを削除してリポジトリを縮小する
+// backupDirが指定されている場合のみ、bundleを作成した上でrefs/originalを削除する
+func PruneRepository(gitDir, backupDir string) (*PruneReport, error) {
+	fmt.Println("\n--- 書き換え後のクリーンアップ ---")
+
+	gitPath := filepath.Join(gitDir, ".git")
+	if !utils.FileExists(gitPath) {
+		return nil, fmt.Errorf("エラー: %s はGitリポジトリではありません", gitDir)
+	}
+
+	report := &PruneReport{}
+
+	sizeBefore, err := utils.DirSize(gitPath)
+	if err != nil {
+		return nil, fmt.Errorf("リポジトリサイズ取得エラー: %v", err)
+	}
+	report.SizeBefore = sizeBefore
+
+	// refs/originalはバックアップが存在する場合のみ削除する
+	backupRefs, err := listRefs(gitDir, "refs/original/")
+	if err != nil {
+		return report, err
+	}
+	if len(backupRefs) > 0 {
+		if backupDir == "" {
+			fmt.Println("⚠️  バックアップ先が指定されていないため、refs/originalを保持します（--backup-dir で指定できます）。")
+		} else {
+			backupPath, err := createBackupBundle(gitDir, backupDir)
+			if err != nil {
+				return report, err
+			}
+			report.BackupPath = backupPath
+			fmt.Printf("✅ バックアップを作成しました: %s\n", backupPath)
+
+			for _, ref := range backupRefs {
+				if _, stderr, err := utils.RunCommand(gitDir, "git", "update-ref", "-d", ref); err != nil {
+					return report, fmt.Errorf("バックアップ参照の削除エラー: %s: %v\nstderr: %s", ref, err, stderr)
+				}
+			}
+			report.BackupRefsRemoved = true
+			fmt.Printf("✅ refs/original の %d 件の参照を削除しました。\n", len(backupRefs))
+		}
+	}
+
+	// reflogを削除する（stashはreflogで管理されているため対象外）
+	refs, err := listRefs(gitDir, "")
+	if err != nil {
+		return report, err
+	}
+	refs = append(refs, "HEAD")
+	for _, ref := range refs {
+		if ref == "refs/stash" || !utils.FileExists(filepath.Join(gitPath, "logs", ref)) {
+			continue
+		}
+		if _, stderr, err := utils.RunCommand(gitDir, "git", "reflog", "expire", "--expire=now", "--expire-unreachable=now", ref); err != nil {
+			return report, fmt.Errorf("reflog削除エラー: %s: %v\nstderr: %s", ref, err, stderr)
+		}
+		report.ExpiredReflogCount++
+	}
+	fmt.Println("✅ reflogを削除しました。")
+
+	// stashのコミットは書き換え前のコミットを親に持つため、stashが残っていると旧履歴も削除されない
+	if _, _, err := utils.RunCommand(gitDir, "git", "rev-parse", "--verify", "--quiet", "refs/stash"); err == nil {
+		report.StashKept = true
+		fmt.Println("⚠️  stashが残っているため、stashから参照される書き換え前のコミット（旧author/email）は削除されません。")
+	}
+
+	// 到達不能なオブジェクトを削除
+	fmt.Println("git gc を実行しています...")
+	if _, stderr, err := utils.RunCommand(gitDir, "git", "gc", "--prune=now", "--quiet"); err != nil {
+		return report, fmt.Errorf("git gc エラー: %v\nstderr: %s", err, stderr)
+	}
+
+	sizeAfter, err := utils.DirSize(gitPath)
+	if err != nil {
+		return report, fmt.Errorf("リポジトリサイズ取得エラー: %v", err)
+	}
+	report.SizeAfter = sizeAfter
+
+	fmt.Printf("✅ クリーンアップが完了しました: %s → %s\n", utils.FormatBytes(report.SizeBefore), utils.FormatBytes(report.SizeAfter))
+	return report, nil
+}
+
+// listRefs は指定されたプレフィックスに一致する参照の一覧を取得する
+func listRefs(gitDir, prefix string) ([]string, error) {
+	args := []string{"for-each-ref", "--format=%(refname)"}
+	if prefix != "" {
+		args = append(args, prefix)
+	}
+
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", args...)
+	if err != nil {
+		return nil, fmt.Errorf("参照一覧取得エラー: %v\nstderr: %s", err, stderr)
+	}
+
+	var refs []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if line != "" {
+			refs = append(refs, line)
+		}
+	}
+	return refs, nil
+}
+
+// createBackupBundle は全参照を含むbundleをバックアップ先に作成する
+func createBackupBundle(gitDir, backupDir string) (string, error) {
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return "", fmt.Errorf("バックアップディレクトリ作成エラー: %v", err)
+	}
+
+	absBackupDir, err := filepath.Abs(backupDir)
+	if err != nil {
+		return "", fmt.Errorf("バックアップディレクトリのパス解決に失敗しました: %v", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.bundle", filepath.Base(gitDir), time.Now().Format("20060102-150405"))
+	backupPath := filepath.Join(absBackupDir, name)
+
+	if _, stderr, err := utils.RunCommand(gitDir, "git", "bundle", "create", backupPath, "--all"); err != nil {
+		return "", fmt.Errorf("バックアップbundle作成エラー: %v\nstderr: %s", err, stderr)
+	}
+	return backupPath, nil
+}
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// TestPruneRepository は書き換え後のクリーンアップをテストする
+func TestPruneRepository(t *testing.T) {
+	t.Run("バックアップ先あり", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		if err := RewriteHistory(repo, "newuser", "new@example.com"); err != nil {
+			t.Fatalf("RewriteHistoryでエラーが発生しました: %v", err)
+		}
+
+		backupDir := t.TempDir()
+		report, err := PruneRepository(repo, backupDir)
+		if err != nil {
+			t.Fatalf("PruneRepositoryでエラーが発生しました: %v", err)
+		}
+
+		if !report.BackupRefsRemoved {
+			t.Error("refs/originalが削除されていません")
+		}
+		if report.BackupPath == "" || !utils.FileExists(report.BackupPath) {
+			t.Errorf("バックアップbundleが作成されていません: %s", report.BackupPath)
+		}
+		if report.StashKept {
+			t.Error("stashがないのにStashKeptがtrueになっています")
+		}
+		if report.SizeBefore == 0 || report.SizeAfter == 0 {
+			t.Errorf("リポジトリサイズが取得されていません: %+v", report)
+		}
+
+		refs, _ := listRefs(repo, "refs/original/")
+		if len(refs) != 0 {
+			t.Errorf("refs/originalが残っています: %v", refs)
+		}
+
+		// 旧emailがオブジェクトから消えていることを確認
+		stdout, _, _ := utils.RunCommand(repo, "git", "log", "--all", "--format=%ae")
+		if strings.Contains(stdout, "test@example.com") {
+			t.Errorf("旧emailが履歴に残っています: %s", stdout)
+		}
+	})
+
+	t.Run("バックアップ先なし", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		if err := RewriteHistory(repo, "newuser", "new@example.com"); err != nil {
+			t.Fatalf("RewriteHistoryでエラーが発生しました: %v", err)
+		}
+
+		report, err := PruneRepository(repo, "")
+		if err != nil {
+			t.Fatalf("PruneRepositoryでエラーが発生しました: %v", err)
+		}
+		if report.BackupRefsRemoved {
+			t.Error("バックアップ先なしでrefs/originalが削除されました")
+		}
+
+		refs, _ := listRefs(repo, "refs/original/")
+		if len(refs) == 0 {
+			t.Error("refs/originalが保持されていません")
+		}
+	})
+
+	t.Run("stashが残っている", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		os.WriteFile(filepath.Join(repo, "file.txt"), []byte("changed\n"), 0644)
+		if _, stderr, err := utils.RunCommand(repo, "git", "stash"); err != nil {
+			t.Fatalf("git stash エラー: %v, stderr: %s", err, stderr)
+		}
+		if err := RewriteHistory(repo, "newuser", "new@example.com"); err != nil {
+			t.Fatalf("RewriteHistoryでエラーが発生しました: %v", err)
+		}
+
+		report, err := PruneRepository(repo, t.TempDir())
+		if err != nil {
+			t.Fatalf("PruneRepositoryでエラーが発生しました: %v", err)
+		}
+		if !report.StashKept {
+			t.Error("stashが残っていることが報告されていません")
+		}
+	})
+
+	t.Run("Gitリポジトリでないディレクトリ", func(t *testing.T) {
+		if _, err := PruneRepository(t.TempDir(), ""); err == nil {
+			t.Error("Gitリポジトリでないディレクトリでエラーが期待されました")
+		}
+	})
+}
@@ -7,17 +7,125 @@ import (
 	"git-rewrite/pkg/utils"
 )
 
-// PushAllBranchesAndTags はローカルの全ブランチとタグをリモートにプッシュする
+// GitHubがプッシュを拒否した際のエラーコード
+const (
+	privateEmailErrorCode = "GH007" // メールアドレスの非公開設定
+	largeFileErrorCode    = "GH001" // 100MBを超えるファイル
+)
+
+// PrivateEmailError はコミットのメールアドレスが非公開設定のためプッシュが拒否されたことを表す
+type PrivateEmailError struct {
+	Stderr string
+}
+
+// Error はerrorインターフェースを実装する
+func (e *PrivateEmailError) Error() string {
+	return fmt.Sprintf("プッシュが拒否されました（%s: メールアドレスが非公開に設定されています）\nstderr: %s", privateEmailErrorCode, e.Stderr)
+}
+
+// IsPrivateEmailRejection はプッシュのエラー出力がGH007による拒否かどうかを判定する
+func IsPrivateEmailRejection(stderr string) bool {
+	return strings.Contains(stderr, privateEmailErrorCode)
+}
+
+// printPrivateEmailGuidance はGH007で拒否された場合の対処方法を表示する
+func printPrivateEmailGuidance() {
+	fmt.Printf("❌ GitHubがプッシュを拒否しました（%s: メールアドレスが非公開に設定されています）。\n", privateEmailErrorCode)
+	fmt.Println("   強制プッシュでは解決しないため、再試行は行いません。以下のいずれかで対処してください:")
+	fmt.Println("   1. --noreply-email オプションを指定し、noreplyメールアドレス（ID+login@users.noreply.github.com）で書き換える")
+	fmt.Println("   2. --email にGitHubのnoreplyメールアドレスを指定して再実行する")
+	fmt.Println("   3. GitHubの Settings > Emails で「Block command line pushes that expose my email」を無効にする")
+}
+
+// LargeFileError はGitHubのファイルサイズ制限を超えるファイルが含まれるためプッシュが拒否されたことを表す
+type LargeFileError struct {
+	Stderr string
+}
+
+// Error はerrorインターフェースを実装する
+func (e *LargeFileError) Error() string {
+	return fmt.Sprintf("プッシュが拒否されました（%s: ファイルサイズの上限を超えるファイルが含まれています）\nstderr: %s", largeFileErrorCode, e.Stderr)
+}
+
+// IsLargeFileRejection はプッシュのエラー出力がGH001による拒否かどうかを判定する
+func IsLargeFileRejection(stderr string) bool {
+	return strings.Contains(stderr, largeFileErrorCode)
+}
+
+// printLargeFileGuidance はGH001で拒否された場合の対処方法を表示する
+func printLargeFileGuidance() {
+	fmt.Printf("❌ GitHubがプッシュを拒否しました（%s: 100MBを超えるファイルが含まれています）。\n", largeFileErrorCode)
+	fmt.Println("   強制プッシュでは解決しないため、再試行は行いません。以下のいずれかで対処してください:")
+	fmt.Println("   1. --lfs-migrate 100MB を指定し、大きなファイルをGit LFSに移行して再実行する")
+	fmt.Println("   2. --lfs-migrate にファイルのパターン（例: '*.zip,*.psd'）を指定して再実行する")
+}
+
+// pushRejection は強制プッシュでは解決しない理由で拒否された場合、対処方法を表示してエラーを返す
+func pushRejection(stderr string) error {
+	switch {
+	case IsPrivateEmailRejection(stderr):
+		printPrivateEmailGuidance()
+		return &PrivateEmailError{Stderr: stderr}
+	case IsLargeFileRejection(stderr):
+		printLargeFileGuidance()
+		return &LargeFileError{Stderr: stderr}
+	}
+	return nil
+}
+
+// PushURLProvider はリモートURLから認証情報付きのプッシュ用URLを生成する
+type PushURLProvider interface {
+	PushURL(remoteURL string) (string, error)
+}
+
+// TokenAuth はGitHubのトークンで認証するPushURLProvider（空の場合は認証情報を付けない）
+type TokenAuth string
+
+// PushURL はトークン付きHTTPS URLを返す
+func (t TokenAuth) PushURL(remoteURL string) (string, error) {
+	if t == "" {
+		return remoteURL, nil
+	}
+	return utils.ConvertToTokenURL(remoteURL, string(t))
+}
+
+// runGitWithAuth はリモートのURLを一時的に認証情報付きURLに変更してGitコマンドを実行する
+// authがnilの場合は通常のコマンドを実行する
+func runGitWithAuth(gitDir, remote string, auth PushURLProvider, args ...string) (string, string, error) {
+	if auth == nil {
+		return utils.RunCommand(gitDir, "git", args...)
+	}
+
+	stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", remote)
+	if err != nil {
+		return "", "", fmt.Errorf("リモートURL取得エラー: %v", err)
+	}
+	pushURL, err := auth.PushURL(strings.TrimSpace(stdout))
+	if err != nil {
+		return "", "", fmt.Errorf("プッシュURL生成エラー: %v", err)
+	}
+	return utils.RunGitWithURL(gitDir, remote, pushURL, args...)
+}
+
+// PushAllBranchesAndTags はローカルの全ブランチとタグをoriginにプッシュする
 func PushAllBranchesAndTags(gitDir, token string) error {
-	fmt.Println("\n--- 全ブランチ・タグのプッシュ ---")
+	return PushAllBranchesAndTagsToRemote(gitDir, DefaultRemote, TokenAuth(token))
+}
+
+// PushAllBranchesAndTagsToRemote はローカルの全ブランチとタグを指定したリモートにプッシュする
+func PushAllBranchesAndTagsToRemote(gitDir, remote string, auth PushURLProvider) error {
+	fmt.Printf("\n--- 全ブランチ・タグのプッシュ（%s） ---\n", remote)
 
-	// 全ブランチをプッシュ（トークン認証使用）
+	// 全ブランチをプッシュ（認証情報付きURL使用）
 	fmt.Println("🌿 全ブランチをプッシュしています...")
-	stdout, stderr, err := utils.RunGitPushWithToken(gitDir, token, "--all", "origin")
+	stdout, stderr, err := runGitWithAuth(gitDir, remote, auth, "push", "--all", remote)
 	if err != nil {
+		if rejection := pushRejection(stderr); rejection != nil {
+			return rejection
+		}
 		// エラーが発生した場合でも、強制プッシュを試行
 		fmt.Println("⚠️  通常のブランチプッシュでエラーが発生しました。強制プッシュを試行します...")
-		stdout, stderr, err = utils.RunGitPushWithToken(gitDir, token, "--force", "--all", "origin")
+		stdout, stderr, err = runGitWithAuth(gitDir, remote, auth, "push", "--force", "--all", remote)
 		if err != nil {
 			fmt.Printf("❌ 全ブランチの強制プッシュに失敗しました: %v\n", err)
 			if stderr != "" {
@@ -35,13 +143,16 @@ func PushAllBranchesAndTags(gitDir, token string) error {
 		fmt.Printf("ブランチプッシュ結果: %s\n", stdout)
 	}
 
-	// 全タグをプッシュ（トークン認証使用）
+	// 全タグをプッシュ（認証情報付きURL使用）
 	fmt.Println("🏷️  全タグをプッシュしています...")
-	stdout, stderr, err = utils.RunGitPushWithToken(gitDir, token, "--tags", "origin")
+	stdout, stderr, err = runGitWithAuth(gitDir, remote, auth, "push", "--tags", remote)
 	if err != nil {
+		if rejection := pushRejection(stderr); rejection != nil {
+			return rejection
+		}
 		// エラーが発生した場合でも、強制プッシュを試行
 		fmt.Println("⚠️  通常のタグプッシュでエラーが発生しました。強制プッシュを試行します...")
-		stdout, stderr, err = utils.RunGitPushWithToken(gitDir, token, "--force", "--tags", "origin")
+		stdout, stderr, err = runGitWithAuth(gitDir, remote, auth, "push", "--force", "--tags", remote)
 		if err != nil {
 			fmt.Printf("❌ 全タグの強制プッシュに失敗しました: %v\n", err)
 			if stderr != "" {
@@ -63,8 +174,13 @@ func PushAllBranchesAndTags(gitDir, token string) error {
 	return nil
 }
 
-// PushToRemote はリモートにプッシュする
+// PushToRemote はoriginにプッシュする
 func PushToRemote(gitDir, token string) error {
+	return PushToNamedRemote(gitDir, DefaultRemote, TokenAuth(token))
+}
+
+// PushToNamedRemote は現在のブランチを指定したリモートにプッシュする
+func PushToNamedRemote(gitDir, remote string, auth PushURLProvider) error {
 	// 現在のブランチを取得
 	stdout, _, err := utils.RunCommand(gitDir, "git", "branch", "--show-current")
 	if err != nil {
@@ -74,13 +190,17 @@ func PushToRemote(gitDir, token string) error {
 	currentBranch := strings.TrimSpace(stdout)
 	fmt.Printf("現在のブランチ: %s\n", currentBranch)
 
-	// git push origin HEADを実行（トークン認証使用）
+	// git push <remote> HEADを実行（認証情報付きURL使用）
 	fmt.Println("リモートにプッシュしています...")
-	stdout, stderr, err := utils.RunGitPushWithToken(gitDir, token, "origin", "HEAD")
+	stdout, stderr, err := runGitWithAuth(gitDir, remote, auth, "push", remote, "HEAD")
 	if err != nil {
+		// メールアドレスの非公開設定やファイルサイズによる拒否は強制プッシュしても解決しない
+		if rejection := pushRejection(stderr); rejection != nil {
+			return rejection
+		}
 		// pushでエラーが出る場合は force pushを試行
 		fmt.Println("⚠️  プッシュエラーが発生しました。強制的にプッシュを試行します...")
-		stdout, stderr, err = utils.RunGitPushWithToken(gitDir, token, "--force", "origin", "HEAD")
+		stdout, stderr, err = runGitWithAuth(gitDir, remote, auth, "push", "--force", remote, "HEAD")
 		if err != nil {
 			return fmt.Errorf("強制プッシュエラー: %v\nstderr: %s", err, stderr)
 		}
@@ -1,8 +1,12 @@
 package git
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
+
+	"git-rewrite/pkg/utils"
 )
 
 // TestPushAllBranchesAndTags はPushAllBranchesAndTags関数をテストする
@@ -123,3 +127,67 @@ func TestPushFunctionParameters(t *testing.T) {
 		}
 	})
 }
+
+// TestPushToRemotePrivateEmailRejection はGH007で拒否された場合に強制プッシュせずにエラーを返すことをテストする
+func TestPushToRemotePrivateEmailRejection(t *testing.T) {
+	repo := setupPreflightRepo(t)
+
+	// GitHubと同じGH007メッセージでプッシュを拒否するリモートを作成
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if _, stderr, err := utils.RunCommand(repo, "git", "init", "--bare", remote); err != nil {
+		t.Fatalf("git init --bare エラー: %v, stderr: %s", err, stderr)
+	}
+	hook := "#!/bin/sh\necho 'error: GH007: Your push would publish a private email address.' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(remote, "hooks", "pre-receive"), []byte(hook), 0755); err != nil {
+		t.Fatalf("フック作成エラー: %v", err)
+	}
+	if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", "origin", remote); err != nil {
+		t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+	}
+
+	err := PushToRemote(repo, "")
+	var privateEmailErr *PrivateEmailError
+	if !errors.As(err, &privateEmailErr) {
+		t.Fatalf("PrivateEmailErrorが期待されました: %v", err)
+	}
+	if !IsPrivateEmailRejection(privateEmailErr.Stderr) {
+		t.Errorf("エラー出力にGH007が含まれていません: %s", privateEmailErr.Stderr)
+	}
+
+	if IsPrivateEmailRejection("error: failed to push some refs") {
+		t.Error("GH007以外のエラーがメールアドレスの拒否と判定されました")
+	}
+}
+
+// TestPushToRemoteLargeFileRejection はGH001で拒否された場合に強制プッシュせずにエラーを返すことをテストする
+func TestPushToRemoteLargeFileRejection(t *testing.T) {
+	repo := setupPreflightRepo(t)
+
+	// GitHubと同じGH001メッセージでプッシュを拒否するリモートを作成
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if _, stderr, err := utils.RunCommand(repo, "git", "init", "--bare", remote); err != nil {
+		t.Fatalf("git init --bare エラー: %v, stderr: %s", err, stderr)
+	}
+	hook := "#!/bin/sh\necho 'remote: error: GH001: Large files detected. You may want to try Git Large File Storage.' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(remote, "hooks", "pre-receive"), []byte(hook), 0755); err != nil {
+		t.Fatalf("フック作成エラー: %v", err)
+	}
+	if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", "origin", remote); err != nil {
+		t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+	}
+
+	err := PushToRemote(repo, "")
+	var largeFileErr *LargeFileError
+	if !errors.As(err, &largeFileErr) {
+		t.Fatalf("LargeFileErrorが期待されました: %v", err)
+	}
+
+	err = PushAllBranchesAndTags(repo, "")
+	if !errors.As(err, &largeFileErr) {
+		t.Fatalf("全ブランチのプッシュでLargeFileErrorが期待されました: %v", err)
+	}
+
+	if IsLargeFileRejection("error: failed to push some refs") {
+		t.Error("GH001以外のエラーがファイルサイズによる拒否と判定されました")
+	}
+}
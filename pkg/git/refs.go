@@ -0,0 +1,258 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"git-rewrite/pkg/utils"
+)
+
+// 参照の種類
+const (
+	RefTypeBranch = "branch"
+	RefTypeTag    = "tag"
+)
+
+// 参照の名前空間
+const (
+	branchRefPrefix = "refs/heads/"
+	tagRefPrefix    = "refs/tags/"
+)
+
+// RefRenamer はブランチ・タグの変更後の名前を決定する
+// 削除する場合はfalseを返す
+type RefRenamer interface {
+	RenameRef(refType, name string) (string, bool)
+}
+
+// RefChange はブランチ・タグ名の変更内容を表す
+type RefChange struct {
+	Type    string // RefTypeBranch または RefTypeTag
+	Old     string // 変更前の名前
+	New     string // 変更後の名前（削除する場合は空）
+	Dropped bool   // 削除したかどうか
+}
+
+// refUpdate は変更を適用するために必要な情報を保持する
+type refUpdate struct {
+	RefChange
+	current string // 変更前の参照が指すオブジェクト
+	object  string // 新しい参照が指すオブジェクト
+}
+
+// tagSignatureMarkers はタグオブジェクトの本文に付加される署名の開始行
+// PGP・SSH・X.509（gpgsm）のいずれの署名も名前の変更で無効になるため取り除く
+var tagSignatureMarkers = []string{
+	"-----BEGIN PGP SIGNATURE-----",
+	"-----BEGIN PGP MESSAGE-----",
+	"-----BEGIN SSH SIGNATURE-----",
+	"-----BEGIN SIGNED MESSAGE-----",
+}
+
+// RenameRefs はルールに従ってブランチ・タグの名前を変更する
+// 名前の衝突や現在のブランチの削除がある場合は、いずれの参照も変更せずにエラーを返す
+func RenameRefs(gitDir string, renamer RefRenamer) ([]RefChange, error) {
+	currentBranch, _, _ := utils.RunCommand(gitDir, "git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	currentBranch = strings.TrimSpace(currentBranch)
+
+	var updates []refUpdate
+	existing := make(map[string]bool)
+	for _, namespace := range []struct{ refType, prefix string }{
+		{RefTypeBranch, branchRefPrefix},
+		{RefTypeTag, tagRefPrefix},
+	} {
+		refs, err := listRefs(gitDir, namespace.prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			existing[ref] = true
+			name := strings.TrimPrefix(ref, namespace.prefix)
+			newName, keep := renamer.RenameRef(namespace.refType, name)
+			if keep && newName == name {
+				continue
+			}
+			change := RefChange{Type: namespace.refType, Old: name, New: newName, Dropped: !keep}
+			if !keep {
+				change.New = ""
+			}
+			updates = append(updates, refUpdate{RefChange: change})
+		}
+	}
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	// 変更後の名前の衝突を確認する
+	renamed := make(map[string]bool)
+	for _, update := range updates {
+		renamed[refName(update.Type, update.Old)] = true
+	}
+	targets := make(map[string]string)
+	for _, update := range updates {
+		if update.Dropped {
+			if update.Type == RefTypeBranch && update.Old == currentBranch {
+				return nil, fmt.Errorf("現在のブランチ %s は削除できません", update.Old)
+			}
+			continue
+		}
+		target := refName(update.Type, update.New)
+		if other, exists := targets[target]; exists {
+			return nil, fmt.Errorf("%s と %s が同じ名前 %s に変更されます", other, update.Old, update.New)
+		}
+		if existing[target] && !renamed[target] {
+			return nil, fmt.Errorf("%s の変更後の名前 %s は既に存在します", update.Old, update.New)
+		}
+		if _, _, err := utils.RunCommand(gitDir, "git", "check-ref-format", target); err != nil {
+			return nil, fmt.Errorf("%s の変更後の名前 %s は参照名として不正です", update.Old, update.New)
+		}
+		targets[target] = update.Old
+	}
+
+	// 新しい参照が指すオブジェクトを準備する（注釈付きタグは新しい名前でタグオブジェクトを作り直す）
+	for i := range updates {
+		ref := refName(updates[i].Type, updates[i].Old)
+		stdout, stderr, err := utils.RunCommand(gitDir, "git", "rev-parse", "--verify", ref)
+		if err != nil {
+			return nil, fmt.Errorf("参照取得エラー: %s: %v\nstderr: %s", ref, err, stderr)
+		}
+		updates[i].current = strings.TrimSpace(stdout)
+		if updates[i].Dropped {
+			continue
+		}
+		object, err := renamedRefObject(gitDir, updates[i].RefChange, updates[i].current)
+		if err != nil {
+			return nil, err
+		}
+		updates[i].object = object
+	}
+
+	// 途中で失敗しても参照が失われないよう、すべての変更を1つのトランザクションで適用する
+	// 入れ替え（a→b, b→a）の場合は同じ参照を削除・作成せず、変更前の値を確認して更新する
+	if err := applyRefTransaction(gitDir, updates); err != nil {
+		return nil, err
+	}
+
+	var changes []RefChange
+	for _, update := range updates {
+		changes = append(changes, update.RefChange)
+		if update.Type == RefTypeBranch && update.Old == currentBranch && !update.Dropped {
+			ref := refName(update.Type, update.New)
+			if _, stderr, err := utils.RunCommand(gitDir, "git", "symbolic-ref", "HEAD", ref); err != nil {
+				return changes, fmt.Errorf("HEAD更新エラー: %v\nstderr: %s", err, stderr)
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// applyRefTransaction は参照の変更を git update-ref --stdin のトランザクションとして適用する
+func applyRefTransaction(gitDir string, updates []refUpdate) error {
+	currents := make(map[string]string)
+	for _, update := range updates {
+		currents[refName(update.Type, update.Old)] = update.current
+	}
+
+	var commands strings.Builder
+	commands.WriteString("start\n")
+	targets := make(map[string]bool)
+	for _, update := range updates {
+		if update.Dropped {
+			continue
+		}
+		ref := refName(update.Type, update.New)
+		targets[ref] = true
+		if current, exists := currents[ref]; exists {
+			fmt.Fprintf(&commands, "update %s %s %s\n", ref, update.object, current)
+		} else {
+			fmt.Fprintf(&commands, "create %s %s\n", ref, update.object)
+		}
+	}
+	for _, update := range updates {
+		ref := refName(update.Type, update.Old)
+		if !targets[ref] {
+			fmt.Fprintf(&commands, "delete %s %s\n", ref, update.current)
+		}
+	}
+	commands.WriteString("prepare\ncommit\n")
+
+	cmd := exec.Command("git", "update-ref", "--stdin")
+	cmd.Dir = gitDir
+	cmd.Stdin = strings.NewReader(commands.String())
+	var errOutput bytes.Buffer
+	cmd.Stderr = &errOutput
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("参照更新エラー（参照は変更されていません）: %v\nstderr: %s", err, utils.SafeDecode(errOutput.Bytes()))
+	}
+	return nil
+}
+
+// renamedRefObject は変更後の参照が指すオブジェクトを返す
+// 注釈付きタグはタグオブジェクト内の名前も変更する必要があるため、新しいタグオブジェクトを作成する
+func renamedRefObject(gitDir string, change RefChange, object string) (string, error) {
+	ref := refName(change.Type, change.Old)
+	if change.Type != RefTypeTag {
+		return object, nil
+	}
+
+	objectType, _, err := utils.RunCommand(gitDir, "git", "cat-file", "-t", object)
+	if err != nil || strings.TrimSpace(objectType) != "tag" {
+		// 軽量タグ
+		return object, nil
+	}
+
+	content, stderr, err := utils.RunCommand(gitDir, "git", "cat-file", "tag", object)
+	if err != nil {
+		return "", fmt.Errorf("タグ取得エラー: %s: %v\nstderr: %s", ref, err, stderr)
+	}
+	// 名前が変わると署名は無効になるため取り除く
+	content = stripTagSignature(content)
+	content = strings.Replace(content, "\ntag "+change.Old+"\n", "\ntag "+change.New+"\n", 1)
+
+	cmd := exec.Command("git", "mktag")
+	cmd.Dir = gitDir
+	cmd.Stdin = strings.NewReader(content)
+	var output, errOutput bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &errOutput
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("タグ作成エラー: %s: %v\nstderr: %s", change.New, err, utils.SafeDecode(errOutput.Bytes()))
+	}
+	return strings.TrimSpace(output.String()), nil
+}
+
+// stripTagSignature はタグオブジェクトの内容から署名を取り除く
+func stripTagSignature(content string) string {
+	for _, marker := range tagSignatureMarkers {
+		if strings.HasPrefix(content, marker) {
+			return ""
+		}
+		if index := strings.Index(content, "\n"+marker); index >= 0 {
+			content = content[:index+1]
+		}
+	}
+	return content
+}
+
+// refName は参照の種類と名前から完全な参照名を返す
+func refName(refType, name string) string {
+	if refType == RefTypeTag {
+		return tagRefPrefix + name
+	}
+	return branchRefPrefix + name
+}
+
+// FormatRefChange は名前の変更内容を表示用の文字列に変換する
+func FormatRefChange(change RefChange) string {
+	kind := "ブランチ"
+	if change.Type == RefTypeTag {
+		kind = "タグ"
+	}
+	if change.Dropped {
+		return fmt.Sprintf("%s %s を削除", kind, change.Old)
+	}
+	return fmt.Sprintf("%s %s → %s", kind, change.Old, change.New)
+}
@@ -0,0 +1,140 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// mapRenamer はテスト用の名前変更ルール（空文字列は削除を表す）
+type mapRenamer map[string]string
+
+func (m mapRenamer) RenameRef(refType, name string) (string, bool) {
+	newName, exists := m[refType+":"+name]
+	if !exists {
+		return name, true
+	}
+	return newName, newName != ""
+}
+
+// setupRefsRepo はブランチとタグを持つテスト用リポジトリを作成する
+func setupRefsRepo(t *testing.T) string {
+	t.Helper()
+
+	repo := setupPreflightRepo(t)
+	commands := [][]string{
+		{"git", "branch", "-M", "master"},
+		{"git", "branch", "tmp/work"},
+		{"git", "branch", "develop"},
+		{"git", "tag", "v1.0"},
+		{"git", "tag", "-a", "v2.0", "-m", "release 2.0"},
+	}
+	for _, args := range commands {
+		if _, stderr, err := utils.RunCommand(repo, args[0], args[1:]...); err != nil {
+			t.Fatalf("%v エラー: %v, stderr: %s", args, err, stderr)
+		}
+	}
+	return repo
+}
+
+// TestRenameRefs はブランチ・タグ名の変更をテストする
+func TestRenameRefs(t *testing.T) {
+	repo := setupRefsRepo(t)
+
+	renamer := mapRenamer{
+		"branch:master":   "main",
+		"branch:tmp/work": "",
+		"tag:v1.0":        "legacy-v1.0",
+		"tag:v2.0":        "legacy-v2.0",
+	}
+	changes, err := RenameRefs(repo, renamer)
+	if err != nil {
+		t.Fatalf("RenameRefsでエラーが発生しました: %v", err)
+	}
+	if len(changes) != 4 {
+		t.Errorf("変更数が正しくありません: %+v", changes)
+	}
+
+	refs, _ := listRefs(repo, "")
+	expected := []string{"refs/heads/develop", "refs/heads/main", "refs/tags/legacy-v1.0", "refs/tags/legacy-v2.0"}
+	if strings.Join(refs, ",") != strings.Join(expected, ",") {
+		t.Errorf("参照が正しくありません: %v", refs)
+	}
+
+	// 現在のブランチも追従する
+	head, _, _ := utils.RunCommand(repo, "git", "symbolic-ref", "HEAD")
+	if strings.TrimSpace(head) != "refs/heads/main" {
+		t.Errorf("HEADが変更後のブランチを指していません: %s", head)
+	}
+
+	// 注釈付きタグはタグオブジェクト内の名前も変更される
+	tag, _, _ := utils.RunCommand(repo, "git", "cat-file", "tag", "legacy-v2.0")
+	if !strings.Contains(tag, "\ntag legacy-v2.0\n") || !strings.Contains(tag, "release 2.0") {
+		t.Errorf("タグオブジェクトが正しくありません:\n%s", tag)
+	}
+}
+
+// TestRenameRefsSwap はブランチ名の入れ替えをテストする
+func TestRenameRefsSwap(t *testing.T) {
+	repo := setupRefsRepo(t)
+	utils.RunCommand(repo, "git", "commit", "--allow-empty", "-m", "develop only")
+	developBefore, _, _ := utils.RunCommand(repo, "git", "rev-parse", "master")
+
+	if _, err := RenameRefs(repo, mapRenamer{"branch:master": "develop", "branch:develop": "master"}); err != nil {
+		t.Fatalf("RenameRefsでエラーが発生しました: %v", err)
+	}
+
+	developAfter, _, _ := utils.RunCommand(repo, "git", "rev-parse", "develop")
+	if developBefore != developAfter {
+		t.Error("ブランチが正しく入れ替えられていません")
+	}
+}
+
+// TestRenameRefsConflicts は名前の衝突などで参照が変更されないことをテストする
+func TestRenameRefsConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		renamer mapRenamer
+	}{
+		{"既存の参照と衝突", mapRenamer{"branch:master": "develop"}},
+		{"変更後の名前が重複", mapRenamer{"branch:master": "main", "branch:develop": "main"}},
+		{"現在のブランチの削除", mapRenamer{"branch:master": ""}},
+		{"不正な参照名", mapRenamer{"branch:develop": "bad..name"}},
+		{"既存のブランチと階層が衝突", mapRenamer{"branch:develop": "master/develop"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setupRefsRepo(t)
+			before, _ := listRefs(repo, "")
+
+			if _, err := RenameRefs(repo, tt.renamer); err == nil {
+				t.Error("エラーが期待されました")
+			}
+
+			after, _ := listRefs(repo, "")
+			if strings.Join(before, ",") != strings.Join(after, ",") {
+				t.Errorf("エラー時に参照が変更されました: %v → %v", before, after)
+			}
+		})
+	}
+}
+
+// TestStripTagSignature は種類の異なる署名がタグオブジェクトから取り除かれることをテストする
+func TestStripTagSignature(t *testing.T) {
+	header := "object 0123456789abcdef0123456789abcdef01234567\ntype commit\ntag v1.0\ntagger Test <test@example.com> 1700000000 +0000\n\nrelease\n"
+	for _, marker := range []string{
+		"-----BEGIN PGP SIGNATURE-----",
+		"-----BEGIN SSH SIGNATURE-----",
+		"-----BEGIN SIGNED MESSAGE-----",
+	} {
+		signed := header + marker + "\nAAAA\n-----END SIGNATURE-----\n"
+		if stripped := stripTagSignature(signed); stripped != header {
+			t.Errorf("%s の署名が取り除かれていません: %q", marker, stripped)
+		}
+	}
+	if stripped := stripTagSignature(header); stripped != header {
+		t.Errorf("署名のないタグが変更されました: %q", stripped)
+	}
+}
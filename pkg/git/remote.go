@@ -3,52 +3,291 @@ package git
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"git-rewrite/pkg/utils"
 )
 
-// UpdateRemoteURL はリモートURLを更新する
+// DefaultRemote はリモート名が指定されていない場合に使用するリモート
+const DefaultRemote = "origin"
+
+// RepoNamer は書き換え先のリポジトリ名を決定する
+type RepoNamer interface {
+	RepoName(owner, repo string) (string, error)
+}
+
+// RemoteHosts はリモートURLの書き換え元と書き換え先のホスト
+type RemoteHosts struct {
+	Sources     []utils.Host // 書き換え対象とするリモートのホスト（Destinationは常に対象とする）
+	Destination utils.Host   // 書き換え先のホスト
+}
+
+// DefaultRemoteHosts はgithub.comのリモートをgithub.com上で書き換える設定を返す
+func DefaultRemoteHosts() RemoteHosts {
+	return RemoteHosts{Destination: utils.DefaultHost}
+}
+
+// ParseSource は書き換え対象のホストのリモートURLを解析する
+func (h RemoteHosts) ParseSource(raw string) (utils.RemoteURL, error) {
+	remote, err := utils.ParseRemoteURL(raw)
+	if err != nil {
+		return utils.RemoteURL{}, err
+	}
+
+	hosts := append([]utils.Host{h.Destination}, h.Sources...)
+	var names []string
+	for _, host := range hosts {
+		if host.Matches(remote) {
+			return remote, nil
+		}
+		names = append(names, host.Name)
+	}
+	return utils.RemoteURL{}, fmt.Errorf("書き換え対象のホスト（%s）のリポジトリではありません: %s", strings.Join(names, ", "), raw)
+}
+
+// UpdateRemoteURL はoriginのリモートURLを更新する
 func UpdateRemoteURL(gitDir, githubUser, owner, organization string) error {
+	return UpdateNamedRemoteURL(gitDir, DefaultRemote, DefaultRemoteHosts(), githubUser, owner, organization, nil)
+}
+
+// UpdateNamedRemoteURL は指定したリモートのURLを書き換え先のホストのURLに更新する
+// namerが指定されている場合はリポジトリ名も変更する
+func UpdateNamedRemoteURL(gitDir, remote string, hosts RemoteHosts, githubUser, owner, organization string, namer RepoNamer) error {
 	targetOwner := utils.GetTargetOwner(githubUser, owner, organization)
-	fmt.Printf("[2/2] Git remoteのorganization部分を%sに変更します...\n", targetOwner)
+	fmt.Printf("[2/2] Git remote（%s）のorganization部分を%sに変更します...\n", remote, targetOwner)
 
-	// remote originが存在するかチェック
-	stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", "origin")
+	// リモートが存在するかチェック
+	stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", remote)
 	if err != nil {
-		fmt.Println("警告: remote originが設定されていません。スキップします。")
+		fmt.Printf("警告: remote %sが設定されていません。スキップします。\n", remote)
 		return nil
 	}
 
-	remoteURL := strings.TrimSpace(stdout)
-	fmt.Printf("現在のremote URL: %s\n", remoteURL)
+	return updateRemote(gitDir, remote, strings.TrimSpace(stdout), hosts, githubUser, owner, organization, namer)
+}
+
+// UpdateAllRemoteURLs は書き換え対象のホストを指す全リモートのURLを更新する
+// それ以外のホストを指すリモートは変更しない
+func UpdateAllRemoteURLs(gitDir string, hosts RemoteHosts, githubUser, owner, organization string, namer RepoNamer) error {
+	targetOwner := utils.GetTargetOwner(githubUser, owner, organization)
+	fmt.Printf("[2/2] 全てのGit remoteのorganization部分を%sに変更します...\n", targetOwner)
+
+	remotes, err := ListRemotes(gitDir)
+	if err != nil {
+		return err
+	}
+	if len(remotes) == 0 {
+		fmt.Println("警告: remoteが設定されていません。スキップします。")
+		return nil
+	}
+
+	for _, remote := range remotes {
+		if IsOldRemote(gitDir, remote) {
+			fmt.Printf("remote %s は移行前のリモートのためスキップします\n", remote)
+			continue
+		}
+		stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", remote)
+		if err != nil {
+			continue
+		}
+		remoteURL := strings.TrimSpace(stdout)
+		if _, err := hosts.ParseSource(remoteURL); err != nil {
+			fmt.Printf("remote %s は書き換え対象のホストのリポジトリではないためスキップします: %s\n", remote, remoteURL)
+			continue
+		}
+		if err := updateRemote(gitDir, remote, remoteURL, hosts, githubUser, owner, organization, namer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddMissingRemote はリモートが1つも設定されていないリポジトリに、書き換え先の所有者を指すリモートを追加する
+// リポジトリ名はディレクトリ名から決定する。リモートを追加した場合はtrueを返す
+func AddMissingRemote(gitDir, remote string, destination utils.Host, githubUser, owner, organization string, namer RepoNamer) (bool, error) {
+	remotes, err := ListRemotes(gitDir)
+	if err != nil {
+		return false, err
+	}
+	if len(remotes) > 0 {
+		return false, nil
+	}
 
-	// URLを解析して新しいURLを生成
-	newURL, err := generateNewRemoteURL(remoteURL, githubUser, owner, organization)
+	newURL, err := missingRemoteURL(gitDir, destination, githubUser, owner, organization, namer)
 	if err != nil {
-		fmt.Printf("警告: %v\n", err)
+		return false, err
+	}
+	fmt.Printf("[2/2] remoteが設定されていないため、%s を %s として追加します...\n", newURL.String(), remote)
+	if _, stderr, err := utils.RunCommand(gitDir, "git", "remote", "add", remote, newURL.String()); err != nil {
+		return false, fmt.Errorf("remote追加エラー: %v\nstderr: %s", err, stderr)
+	}
+
+	if namer != nil {
+		if err := recordRenamedRemoteURL(gitDir, remote, newURL.String()); err != nil {
+			return true, err
+		}
+	}
+	fmt.Printf("remote %s を追加しました。\n", remote)
+	return true, nil
+}
+
+// missingRemoteURL はリモートがないリポジトリに追加するリモートURLを生成する
+func missingRemoteURL(gitDir string, destination utils.Host, githubUser, owner, organization string, namer RepoNamer) (utils.RemoteURL, error) {
+	repoName, err := repoNameFromDir(gitDir)
+	if err != nil {
+		return utils.RemoteURL{}, err
+	}
+	if namer != nil {
+		// ローカルのみのリポジトリは実行ユーザーのものとして扱う
+		if repoName, err = namer.RepoName(githubUser, repoName); err != nil {
+			return utils.RemoteURL{}, err
+		}
+	}
+
+	targetOwner := utils.GetTargetOwner(githubUser, owner, organization)
+	return destination.RemoteURL(targetOwner, repoName), nil
+}
+
+// PlanRemoteURL はリポジトリを変更せずに、書き換え後のリモートURLを返す
+// リモートが存在しない・書き換え対象のホストのURLではない場合はfalseを返す
+func PlanRemoteURL(gitDir, remote string, hosts RemoteHosts, githubUser, owner, organization string, namer RepoNamer, createMissing bool) (utils.RemoteURL, bool, error) {
+	stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", remote)
+	if err != nil {
+		if !createMissing {
+			return utils.RemoteURL{}, false, nil
+		}
+		// AddMissingRemoteと同様に、リモートが1つもない場合のみ追加する
+		remotes, err := ListRemotes(gitDir)
+		if err != nil || len(remotes) > 0 {
+			return utils.RemoteURL{}, false, err
+		}
+		newURL, err := missingRemoteURL(gitDir, hosts.Destination, githubUser, owner, organization, namer)
+		return newURL, err == nil, err
+	}
+
+	current, err := hosts.ParseSource(strings.TrimSpace(stdout))
+	if err != nil {
+		return utils.RemoteURL{}, false, nil
+	}
+	namer = unrenamedNamer(gitDir, remote, strings.TrimSpace(stdout), namer)
+	newURL, err := destinationRemoteURL(current, hosts.Destination, githubUser, owner, organization, namer)
+	return newURL, err == nil, err
+}
+
+// SetRemoteRepoName はリモートURLのリポジトリ名のみを変更する
+func SetRemoteRepoName(gitDir, remote, repoName string) error {
+	stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", remote)
+	if err != nil {
+		return fmt.Errorf("リモートURL取得エラー: %v", err)
+	}
+	current, err := utils.ParseRemoteURL(strings.TrimSpace(stdout))
+	if err != nil {
+		return fmt.Errorf("remote URLが想定外の形式です: %v", err)
+	}
+	if current.Repo == repoName {
+		return nil
+	}
+
+	fmt.Printf("リポジトリ名を変更します: %s → %s\n", current.Repo, repoName)
+	current.Repo = repoName
+	if _, stderr, err := utils.RunCommand(gitDir, "git", "remote", "set-url", remote, current.String()); err != nil {
+		return fmt.Errorf("remote URL更新エラー: %v\nstderr: %s", err, stderr)
+	}
+	// リポジトリ名のルールを適用済みの記録も更新し、再実行時に二重に適用されないようにする
+	if renamedRemoteURL(gitDir, remote) == strings.TrimSpace(stdout) {
+		if err := recordRenamedRemoteURL(gitDir, remote, current.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repoNameFromDir はディレクトリ名からリポジトリ名を決定する
+// リポジトリ名に使用できない文字は - に置き換える
+func repoNameFromDir(gitDir string) (string, error) {
+	base := filepath.Base(filepath.Clean(gitDir))
+
+	var name strings.Builder
+	for _, r := range base {
+		if utils.IsValidRepoName(string(r)) {
+			name.WriteRune(r)
+		} else if !strings.HasSuffix(name.String(), "-") {
+			name.WriteRune('-')
+		}
+	}
+	repoName := strings.Trim(name.String(), "-.")
+	if repoName == "" {
+		return "", fmt.Errorf("ディレクトリ名 %s からリポジトリ名を決定できません", base)
+	}
+	return repoName, nil
+}
+
+// ListRemotes はリポジトリに設定されているリモート名の一覧を返す
+func ListRemotes(gitDir string) ([]string, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "remote")
+	if err != nil {
+		return nil, fmt.Errorf("remote一覧取得エラー: %v\nstderr: %s", err, stderr)
+	}
+
+	var remotes []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			remotes = append(remotes, line)
+		}
+	}
+	return remotes, nil
+}
+
+// updateRemote はリモートのURLを書き換え先の所有者のURLに変更する
+func updateRemote(gitDir, remote, remoteURL string, hosts RemoteHosts, githubUser, owner, organization string, namer RepoNamer) error {
+	fmt.Printf("現在のremote URL（%s）: %s\n", remote, remoteURL)
+
+	// 解析できない・書き換え対象外のホストのURLは変更せずにスキップする
+	if _, err := hosts.ParseSource(remoteURL); err != nil {
+		fmt.Printf("警告: remote URLが想定外の形式です: %v\n", err)
 		fmt.Println("remote URLの変更をスキップします。")
 		return nil
 	}
 
+	// 新しいURLを生成（リポジトリ名のルールの誤りはエラーにする）
+	newURL, err := generateNewRemoteURL(remoteURL, hosts, githubUser, owner, organization, unrenamedNamer(gitDir, remote, remoteURL, namer))
+	if err != nil {
+		return fmt.Errorf("remote URL生成エラー: %v", err)
+	}
+
 	// リモートURLを更新
-	_, _, err = utils.RunCommand(gitDir, "git", "remote", "set-url", "origin", newURL)
+	_, _, err = utils.RunCommand(gitDir, "git", "remote", "set-url", remote, newURL)
 	if err != nil {
 		return fmt.Errorf("remote URL更新エラー: %v", err)
 	}
+	if namer != nil {
+		if err := recordRenamedRemoteURL(gitDir, remote, newURL); err != nil {
+			return err
+		}
+	}
 
-	fmt.Printf("remote URLを%sに変更しました。\n", newURL)
+	fmt.Printf("remote %s のURLを%sに変更しました。\n", remote, newURL)
 	return nil
 }
 
+// unrenamedNamer はリモートが前回の実行でリポジトリ名のルールを適用したURLのままの場合はnilを、それ以外はnamerを返す
+// 元のオーナーと書き換え先のオーナーが同じ場合もルールを適用しつつ、再実行時に二重に適用しないために使用する
+func unrenamedNamer(gitDir, remote, remoteURL string, namer RepoNamer) RepoNamer {
+	if namer != nil && renamedRemoteURL(gitDir, remote) == remoteURL {
+		return nil
+	}
+	return namer
+}
+
 // generateNewRemoteURL は新しいリモートURLを生成する
-func generateNewRemoteURL(remoteURL, githubUser, owner, organization string) (string, error) {
-	ownerFromURL, repo := utils.ExtractRepoInfoFromURL(remoteURL)
+func generateNewRemoteURL(remoteURL string, hosts RemoteHosts, githubUser, owner, organization string, namer RepoNamer) (string, error) {
+	remote, err := hosts.ParseSource(remoteURL)
 	if os.Getenv("GIT_REWRITE_DEBUG") != "" {
-		fmt.Printf("デバッグ: URL解析結果 - URL: %s, Owner: '%s', Repo: '%s'\n", remoteURL, ownerFromURL, repo)
+		fmt.Printf("デバッグ: URL解析結果 - URL: %s, 結果: %+v, エラー: %v\n", remoteURL, remote, err)
 	}
-	if ownerFromURL == "" || repo == "" {
-		return "", fmt.Errorf("remote URLが想定外の形式です: %s (解析結果: owner='%s', repo='%s')", remoteURL, ownerFromURL, repo)
+	if err != nil {
+		return "", fmt.Errorf("remote URLが想定外の形式です: %v", err)
 	}
 
 	targetOwner := utils.GetTargetOwner(githubUser, owner, organization)
@@ -60,12 +299,38 @@ func generateNewRemoteURL(remoteURL, githubUser, owner, organization string) (st
 		}
 	}
 
-	// HTTPS形式かSSH形式かを判定
-	if strings.HasPrefix(remoteURL, "https://") {
-		return fmt.Sprintf("https://github.com/%s/%s", targetOwner, repo), nil
-	} else if strings.HasPrefix(remoteURL, "git@") {
-		return fmt.Sprintf("git@github.com:%s/%s.git", targetOwner, repo), nil
+	newURL, err := destinationRemoteURL(remote, hosts.Destination, githubUser, owner, organization, namer)
+	if err != nil {
+		return "", err
 	}
+	if newURL.Host != remote.Host {
+		fmt.Printf("ホストを変更します: %s → %s\n", remote.Host, hosts.Destination.Name)
+	}
+	if newURL.Repo != remote.Repo {
+		fmt.Printf("リポジトリ名を変更します: %s → %s\n", remote.Repo, newURL.Repo)
+	}
+	return newURL.String(), nil
+}
 
-	return "", fmt.Errorf("サポートされていないURL形式: %s", remoteURL)
+// destinationRemoteURL は解析済みのリモートURLのオーナー（とリポジトリ名）を書き換え先のものに変更する
+// 書き換え先と同じホストの場合はスキーム・ユーザー・ポートを維持し、別のホストの場合は書き換え先のホストのHTTPS URLにする
+func destinationRemoteURL(remote utils.RemoteURL, destination utils.Host, githubUser, owner, organization string, namer RepoNamer) (utils.RemoteURL, error) {
+	targetOwner := utils.GetTargetOwner(githubUser, owner, organization)
+
+	// 前回の実行で変更済みのURLの場合、呼び出し元でnamerをnilにしている（unrenamedNamer）
+	if namer != nil {
+		repoName, err := namer.RepoName(remote.Owner, remote.Repo)
+		if err != nil {
+			return utils.RemoteURL{}, err
+		}
+		remote.Repo = repoName
+	}
+
+	if !strings.EqualFold(remote.Host, destination.Hostname()) {
+		remote = destination.RemoteURL(targetOwner, remote.Repo)
+	}
+	remote.Owner = targetOwner
+	// HTTP(S)形式は.gitなし、SSH形式は.gitありに統一する
+	remote.GitSuffix = !remote.IsHTTP()
+	return remote, nil
 }
@@ -2,7 +2,11 @@ package git
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"git-rewrite/pkg/utils"
 )
 
 func TestGenerateNewRemoteURL(t *testing.T) {
@@ -84,7 +88,7 @@ func TestGenerateNewRemoteURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := generateNewRemoteURL(tt.remoteURL, tt.githubUser, tt.owner, tt.organization)
+			result, err := generateNewRemoteURL(tt.remoteURL, DefaultRemoteHosts(), tt.githubUser, tt.owner, tt.organization, nil)
 
 			if tt.shouldError {
 				if err == nil {
@@ -133,7 +137,7 @@ func TestGenerateNewRemoteURLWithEnvironmentVariables(t *testing.T) {
 	os.Unsetenv("GITHUB_REPOSITORY_OWNER")
 	os.Unsetenv("GITHUB_ORGANIZATION")
 
-	result, err := generateNewRemoteURL("https://github.com/olduser/repo", "testuser", "", "")
+	result, err := generateNewRemoteURL("https://github.com/olduser/repo", DefaultRemoteHosts(), "testuser", "", "", nil)
 	if err != nil {
 		t.Errorf("予期しないエラー: %v", err)
 		return
@@ -144,3 +148,316 @@ func TestGenerateNewRemoteURLWithEnvironmentVariables(t *testing.T) {
 		t.Errorf("期待されるURL: %s, 実際: %s", expected, result)
 	}
 }
+
+// remoteURLs はリモート名とURLの対応を返す
+func remoteURLs(t *testing.T, repo string) map[string]string {
+	t.Helper()
+
+	remotes, err := ListRemotes(repo)
+	if err != nil {
+		t.Fatalf("ListRemotesでエラーが発生しました: %v", err)
+	}
+	urls := make(map[string]string)
+	for _, remote := range remotes {
+		stdout, _, err := utils.RunCommand(repo, "git", "remote", "get-url", remote)
+		if err != nil {
+			t.Fatalf("git remote get-url エラー: %v", err)
+		}
+		urls[remote] = strings.TrimSpace(stdout)
+	}
+	return urls
+}
+
+// TestUpdateRemoteURLsByName は指定したリモートのみ、またはGitHubを指す全リモートのURLが変更されることをテストする
+func TestUpdateRemoteURLsByName(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+
+	setup := func(t *testing.T) string {
+		repo := setupPreflightRepo(t)
+		for name, url := range map[string]string{
+			"origin":   "https://github.com/someone/fork.git",
+			"upstream": "git@github.com:original/project.git",
+			"mirror":   "https://gitlab.com/original/project.git",
+		} {
+			if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", name, url); err != nil {
+				t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+			}
+		}
+		return repo
+	}
+
+	t.Run("指定したリモートのみ", func(t *testing.T) {
+		repo := setup(t)
+		if err := UpdateNamedRemoteURL(repo, "upstream", DefaultRemoteHosts(), "newuser", "", "neworg", nil); err != nil {
+			t.Fatalf("UpdateNamedRemoteURLでエラーが発生しました: %v", err)
+		}
+		urls := remoteURLs(t, repo)
+		if urls["upstream"] != "git@github.com:neworg/project.git" {
+			t.Errorf("upstreamが変更されていません: %s", urls["upstream"])
+		}
+		if urls["origin"] != "https://github.com/someone/fork.git" {
+			t.Errorf("指定していないoriginが変更されました: %s", urls["origin"])
+		}
+	})
+
+	t.Run("全リモート", func(t *testing.T) {
+		repo := setup(t)
+		if err := UpdateAllRemoteURLs(repo, DefaultRemoteHosts(), "newuser", "", "neworg", nil); err != nil {
+			t.Fatalf("UpdateAllRemoteURLsでエラーが発生しました: %v", err)
+		}
+		expected := map[string]string{
+			"origin":   "https://github.com/neworg/fork",
+			"upstream": "git@github.com:neworg/project.git",
+			"mirror":   "https://gitlab.com/original/project.git",
+		}
+		urls := remoteURLs(t, repo)
+		for name, url := range expected {
+			if urls[name] != url {
+				t.Errorf("%s: 期待値 %s, 実際 %s", name, url, urls[name])
+			}
+		}
+	})
+
+	t.Run("存在しないリモート", func(t *testing.T) {
+		repo := setup(t)
+		if err := UpdateNamedRemoteURL(repo, "missing", DefaultRemoteHosts(), "newuser", "", "neworg", nil); err != nil {
+			t.Errorf("存在しないリモートはスキップされることが期待されました: %v", err)
+		}
+	})
+}
+
+// TestAddMissingRemote はリモートがないリポジトリにディレクトリ名からリモートが追加されることをテストする
+func TestAddMissingRemote(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+
+	t.Run("リモートなし", func(t *testing.T) {
+		repo := filepath.Join(t.TempDir(), "My Proto (old)")
+		if _, stderr, err := utils.RunCommand(filepath.Dir(repo), "git", "init", repo); err != nil {
+			t.Fatalf("git init エラー: %v, stderr: %s", err, stderr)
+		}
+
+		added, err := AddMissingRemote(repo, "origin", utils.DefaultHost, "newuser", "", "neworg", nil)
+		if err != nil || !added {
+			t.Fatalf("リモートが追加されることが期待されました: %v, %v", added, err)
+		}
+		if url := remoteURLs(t, repo)["origin"]; url != "https://github.com/neworg/My-Proto-old" {
+			t.Errorf("追加されたリモートが期待値と異なります: %s", url)
+		}
+	})
+
+	t.Run("リポジトリ名のルール", func(t *testing.T) {
+		repo := filepath.Join(t.TempDir(), "app")
+		if _, stderr, err := utils.RunCommand(filepath.Dir(repo), "git", "init", repo); err != nil {
+			t.Fatalf("git init エラー: %v, stderr: %s", err, stderr)
+		}
+
+		if _, err := AddMissingRemote(repo, "upstream", utils.DefaultHost, "newuser", "", "", prefixNamer("legacy-")); err != nil {
+			t.Fatalf("AddMissingRemoteでエラーが発生しました: %v", err)
+		}
+		if url := remoteURLs(t, repo)["upstream"]; url != "https://github.com/newuser/legacy-app" {
+			t.Errorf("リポジトリ名のルールが適用されていません: %s", url)
+		}
+	})
+
+	t.Run("リモートあり", func(t *testing.T) {
+		repo := setupPreflightRepo(t)
+		if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", "upstream", "https://github.com/someone/app.git"); err != nil {
+			t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+		}
+
+		added, err := AddMissingRemote(repo, "origin", utils.DefaultHost, "newuser", "", "neworg", nil)
+		if err != nil || added {
+			t.Errorf("既存のリモートがある場合は追加しないことが期待されました: %v, %v", added, err)
+		}
+		if urls := remoteURLs(t, repo); len(urls) != 1 {
+			t.Errorf("リモートが変更されました: %v", urls)
+		}
+	})
+}
+
+// TestPlanRemoteURL はリポジトリを変更せずに書き換え後のリモートURLを求められることをテストする
+func TestPlanRemoteURL(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+
+	repo := setupPreflightRepo(t)
+	if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", "origin", "git@github.com:olduser/tools.git"); err != nil {
+		t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+	}
+
+	planned, ok, err := PlanRemoteURL(repo, "origin", DefaultRemoteHosts(), "newuser", "", "neworg", prefixNamer("legacy-"), false)
+	if err != nil || !ok {
+		t.Fatalf("書き換え先が求められることが期待されました: %v, %v", ok, err)
+	}
+	if planned.Owner != "neworg" || planned.Repo != "legacy-tools" {
+		t.Errorf("書き換え先が期待値と異なります: %+v", planned)
+	}
+	if url := remoteURLs(t, repo)["origin"]; url != "git@github.com:olduser/tools.git" {
+		t.Errorf("リモートURLが変更されました: %s", url)
+	}
+
+	if _, ok, err := PlanRemoteURL(repo, "missing", DefaultRemoteHosts(), "newuser", "", "neworg", nil, true); err != nil || ok {
+		t.Errorf("他のリモートがある場合は書き換え先を決定しないことが期待されました: %v, %v", ok, err)
+	}
+
+	if err := SetRemoteRepoName(repo, "origin", "tools-2"); err != nil {
+		t.Fatalf("SetRemoteRepoNameでエラーが発生しました: %v", err)
+	}
+	if url := remoteURLs(t, repo)["origin"]; url != "git@github.com:olduser/tools-2.git" {
+		t.Errorf("リポジトリ名のみ変更されることが期待されました: %s", url)
+	}
+}
+
+// TestPushToNamedRemote はorigin以外のリモートにプッシュできることをテストする
+func TestPushToNamedRemote(t *testing.T) {
+	repo := setupPreflightRepo(t)
+
+	remote := filepath.Join(t.TempDir(), "upstream.git")
+	if _, stderr, err := utils.RunCommand(repo, "git", "init", "--bare", remote); err != nil {
+		t.Fatalf("git init --bare エラー: %v, stderr: %s", err, stderr)
+	}
+	if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", "upstream", remote); err != nil {
+		t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+	}
+
+	if err := PushToNamedRemote(repo, "upstream", nil); err != nil {
+		t.Fatalf("PushToNamedRemoteでエラーが発生しました: %v", err)
+	}
+
+	head, _, _ := utils.RunCommand(repo, "git", "rev-parse", "HEAD")
+	branch, _, _ := utils.RunCommand(repo, "git", "branch", "--show-current")
+	pushed, _, err := utils.RunCommand(remote, "git", "rev-parse", strings.TrimSpace(branch))
+	if err != nil || strings.TrimSpace(pushed) != strings.TrimSpace(head) {
+		t.Errorf("upstreamにプッシュされていません: %s, %v", pushed, err)
+	}
+}
+
+// TestGenerateNewRemoteURLEnterprise はGitHub Enterprise Serverのホストを維持してURLを生成することをテストする
+func TestGenerateNewRemoteURLEnterprise(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+
+	hosts := RemoteHosts{Destination: utils.NewHost("ghe.corp", false)}
+	tests := map[string]string{
+		"https://ghe.corp/olduser/repo.git": "https://ghe.corp/neworg/repo",
+		"git@ghe.corp:olduser/repo.git":     "git@ghe.corp:neworg/repo.git",
+	}
+	for remoteURL, expected := range tests {
+		actual, err := generateNewRemoteURL(remoteURL, hosts, "newuser", "", "neworg", nil)
+		if err != nil {
+			t.Errorf("%s: 予期しないエラー: %v", remoteURL, err)
+			continue
+		}
+		if actual != expected {
+			t.Errorf("%s: 期待値 %s, 実際 %s", remoteURL, expected, actual)
+		}
+	}
+
+	if _, err := generateNewRemoteURL("https://github.com/olduser/repo.git", hosts, "newuser", "", "neworg", nil); err == nil {
+		t.Error("書き換え対象外のホストのURLでエラーが期待されました")
+	}
+}
+
+// TestGenerateNewRemoteURLAcrossHosts は書き換え元と別のホストに移行する場合のURLの生成をテストする
+func TestGenerateNewRemoteURLAcrossHosts(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+
+	tests := []struct {
+		hosts     RemoteHosts
+		remoteURL string
+		expected  string
+	}{
+		{
+			hosts:     RemoteHosts{Sources: []utils.Host{utils.DefaultHost}, Destination: utils.NewHost("ghe.corp", false)},
+			remoteURL: "git@github.com:olduser/repo.git",
+			expected:  "https://ghe.corp/neworg/repo",
+		},
+		{
+			hosts:     RemoteHosts{Sources: []utils.Host{utils.DefaultHost}, Destination: utils.NewHost("gitlab.example.com:8443", true)},
+			remoteURL: "ssh://git@github.com:22/olduser/repo.git",
+			expected:  "https://gitlab.example.com:8443/neworg/repo",
+		},
+		{
+			hosts:     RemoteHosts{Sources: []utils.Host{utils.NewHost("gitlab.old.corp", true)}, Destination: utils.NewHost("gitlab.example.com", true)},
+			remoteURL: "https://gitlab.old.corp/group/sub/repo.git",
+			expected:  "https://gitlab.example.com/neworg/repo",
+		},
+	}
+	for _, tt := range tests {
+		actual, err := generateNewRemoteURL(tt.remoteURL, tt.hosts, "newuser", "", "neworg", nil)
+		if err != nil {
+			t.Errorf("%s: 予期しないエラー: %v", tt.remoteURL, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("%s: 期待値 %s, 実際 %s", tt.remoteURL, tt.expected, actual)
+		}
+	}
+}
+
+// prefixNamer はテスト用にリポジトリ名に接頭辞を付けるRepoNamer
+type prefixNamer string
+
+func (p prefixNamer) RepoName(owner, repo string) (string, error) {
+	return string(p) + repo, nil
+}
+
+// TestGenerateNewRemoteURLWithNamer はリポジトリ名のルールがremote URLに反映されることをテストする
+func TestGenerateNewRemoteURLWithNamer(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+
+	actual, err := generateNewRemoteURL("git@github.com:olduser/app.git", DefaultRemoteHosts(), "newuser", "", "neworg", prefixNamer("legacy-"))
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if actual != "git@github.com:neworg/legacy-app.git" {
+		t.Errorf("リポジトリ名が変更されていません: %s", actual)
+	}
+
+	// 元のオーナーと書き換え先のオーナーが同じでもリポジトリ名は変更する
+	actual, err = generateNewRemoteURL("git@github.com:neworg/app.git", DefaultRemoteHosts(), "newuser", "", "neworg", prefixNamer("legacy-"))
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if actual != "git@github.com:neworg/legacy-app.git" {
+		t.Errorf("同じオーナーのリポジトリ名が変更されていません: %s", actual)
+	}
+}
+
+// TestUpdateNamedRemoteURLWithNamerRerun はリポジトリ名のルールが同じオーナーでも適用され、再実行時に二重に適用されないことをテストする
+func TestUpdateNamedRemoteURLWithNamerRerun(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+
+	repo := setupPreflightRepo(t)
+	if _, stderr, err := utils.RunCommand(repo, "git", "remote", "add", "origin", "https://github.com/neworg/app.git"); err != nil {
+		t.Fatalf("git remote add エラー: %v, stderr: %s", err, stderr)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := UpdateNamedRemoteURL(repo, "origin", DefaultRemoteHosts(), "newuser", "", "neworg", prefixNamer("legacy-")); err != nil {
+			t.Fatalf("UpdateNamedRemoteURLでエラーが発生しました: %v", err)
+		}
+		if url := remoteURLs(t, repo)["origin"]; url != "https://github.com/neworg/legacy-app" {
+			t.Errorf("%d 回目の実行後のURLが期待値と異なります: %s", i+1, url)
+		}
+	}
+
+	planned, ok, err := PlanRemoteURL(repo, "origin", DefaultRemoteHosts(), "newuser", "", "neworg", prefixNamer("legacy-"), false)
+	if err != nil || !ok || planned.Repo != "legacy-app" {
+		t.Errorf("再実行時の書き換え先が期待値と異なります: %+v, %v, %v", planned, ok, err)
+	}
+
+	// 前回の実行後にURLを手動で変更した場合は再度ルールを適用する
+	utils.RunCommand(repo, "git", "remote", "set-url", "origin", "https://github.com/neworg/tools.git")
+	if err := UpdateNamedRemoteURL(repo, "origin", DefaultRemoteHosts(), "newuser", "", "neworg", prefixNamer("legacy-")); err != nil {
+		t.Fatalf("UpdateNamedRemoteURLでエラーが発生しました: %v", err)
+	}
+	if url := remoteURLs(t, repo)["origin"]; url != "https://github.com/neworg/legacy-tools" {
+		t.Errorf("変更したURLにリポジトリ名のルールが適用されていません: %s", url)
+	}
+}
@@ -0,0 +1,286 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"git-rewrite/pkg/utils"
+)
+
+// 履歴を畳み込んだ合成ルートコミットのメッセージ
+const (
+	squashedRootMessage  = "Initial commit"
+	truncatedRootMessage = "Squashed history before %s"
+	truncateDateFormat   = "2006-01-02"
+)
+
+// historyGrafts は履歴の畳み込みのために一時的に作成したreplace参照を表す
+// 畳み込み対象のコミットのうち1つのツリーから合成ルートコミットを作成し、各対象コミットを次のように置き換える
+//   - 合成ルートと同じツリーのコミットは合成ルートそのものに置き換える
+//   - ツリーが異なるコミット（別のブランチの畳み込み対象）は、元のツリーとメッセージのまま合成ルートを親とする
+type historyGrafts struct {
+	root     string            // 合成ルートコミット
+	replaced map[string]string // replace参照を作成したコミット → 元々存在したreplace参照（なければ空）
+}
+
+// prepareHistoryGrafts は--squash-history / --truncate-before のためにreplace参照を作成する
+// 作成したreplace参照はfilter-branchによって履歴に反映された後、cleanupで削除する
+func prepareHistoryGrafts(gitDir string, opts *RewriteOptions) (*historyGrafts, error) {
+	if !opts.SquashHistory && opts.TruncateBefore.IsZero() {
+		return nil, nil
+	}
+
+	tips, err := refTipCommits(gitDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	var message string
+	if opts.SquashHistory {
+		// 各ブランチ・タグの先端を、現在のツリーのみを持つルートコミットにする
+		fmt.Println("履歴を1つのルートコミットに畳み込みます...")
+		targets = tips
+		message = squashedRootMessage
+	} else {
+		fmt.Printf("%s より前の履歴を1つのルートコミットに畳み込みます...\n", opts.TruncateBefore.Format(truncateDateFormat))
+		targets, err = truncationBoundary(gitDir, opts.TruncateBefore, tips)
+		if err != nil {
+			return nil, err
+		}
+		message = fmt.Sprintf(truncatedRootMessage, opts.TruncateBefore.Format(truncateDateFormat))
+	}
+
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	targets, err = sortRootCandidates(gitDir, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	// 現在のブランチの畳み込み対象のツリーから、唯一のルートとなる合成コミットを作成する
+	grafts := &historyGrafts{replaced: make(map[string]string)}
+	grafts.root, err = createRootCommit(gitDir, targets[0], message)
+	if err != nil {
+		return nil, err
+	}
+	rootTree, err := commitTree(gitDir, targets[0])
+	if err != nil {
+		return nil, err
+	}
+
+	grafted := 0
+	for _, commit := range targets {
+		tree, err := commitTree(gitDir, commit)
+		if err != nil {
+			grafts.cleanup(gitDir)
+			return nil, err
+		}
+		args := []string{"replace", "-f", commit, grafts.root}
+		if tree != rootTree {
+			args = []string{"replace", "--graft", "-f", commit, grafts.root}
+			grafted++
+		}
+		if err := grafts.replace(gitDir, commit, args); err != nil {
+			grafts.cleanup(gitDir)
+			return nil, err
+		}
+	}
+
+	if grafted > 0 {
+		fmt.Printf("%d 個のコミットを1つのルートコミットに置き換え、%d 個のコミットをその子コミットとして扱います。\n", len(targets)-grafted, grafted)
+	} else {
+		fmt.Printf("%d 個のコミットを1つのルートコミットに置き換えます。\n", len(targets))
+	}
+	return grafts, nil
+}
+
+// replace はコミットを置き換えるreplace参照を作成する
+// 既存のreplace参照は上書きし、cleanupで元に戻す
+func (g *historyGrafts) replace(gitDir, commit string, args []string) error {
+	existing, _, _ := utils.RunCommand(gitDir, "git", "rev-parse", "--verify", "--quiet", "refs/replace/"+commit)
+	if _, stderr, err := utils.RunCommand(gitDir, "git", args...); err != nil {
+		return fmt.Errorf("replace参照作成エラー: %s: %v\nstderr: %s", commit, err, stderr)
+	}
+	g.replaced[commit] = strings.TrimSpace(existing)
+	return nil
+}
+
+// cleanup は作成したreplace参照を削除し、元々存在したreplace参照を元に戻す
+func (g *historyGrafts) cleanup(gitDir string) {
+	if g == nil {
+		return
+	}
+	for commit, existing := range g.replaced {
+		if existing != "" {
+			utils.RunCommand(gitDir, "git", "update-ref", "refs/replace/"+commit, existing)
+		} else {
+			utils.RunCommand(gitDir, "git", "replace", "-d", commit)
+		}
+	}
+}
+
+// createRootCommit はコミットと同じツリー・author・committerを持ち、親のない合成コミットを作成する
+func createRootCommit(gitDir, commit, message string) (string, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "show", "-s", "--format=%an%x00%ae%x00%ad%x00%cn%x00%ce%x00%cd", "--date=raw", commit)
+	if err != nil {
+		return "", fmt.Errorf("コミット情報取得エラー: %s: %v\nstderr: %s", commit, err, stderr)
+	}
+	fields := strings.Split(strings.TrimSuffix(stdout, "\n"), "\x00")
+	if len(fields) != 6 {
+		return "", fmt.Errorf("コミット情報の解析に失敗しました: %s", commit)
+	}
+
+	cmd := exec.Command("git", "commit-tree", commit+"^{tree}", "-m", message)
+	cmd.Dir = gitDir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+fields[0], "GIT_AUTHOR_EMAIL="+fields[1], "GIT_AUTHOR_DATE="+fields[2],
+		"GIT_COMMITTER_NAME="+fields[3], "GIT_COMMITTER_EMAIL="+fields[4], "GIT_COMMITTER_DATE="+fields[5])
+	var output, errOutput bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &errOutput
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("合成ルートコミット作成エラー: %v\nstderr: %s", err, utils.SafeDecode(errOutput.Bytes()))
+	}
+	return strings.TrimSpace(output.String()), nil
+}
+
+// commitTree はコミットのツリーを返す
+func commitTree(gitDir, commit string) (string, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "rev-parse", commit+"^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("ツリー取得エラー: %s: %v\nstderr: %s", commit, err, stderr)
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+// truncationBoundary は指定日時より前のコミットのうち、畳み込み後にルートとなるコミットを返す
+// 指定日時以降のコミットの親となっている古いコミットと、先端自体が古いブランチ・タグが対象になる
+func truncationBoundary(gitDir string, before time.Time, tips []string) ([]string, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "log", "--branches", "--tags", "--format=%H %at %P")
+	if err != nil {
+		return nil, fmt.Errorf("コミット一覧取得エラー: %v\nstderr: %s", err, stderr)
+	}
+
+	cutoff := before.Unix()
+	old := make(map[string]bool)
+	parents := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		timestamp, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		old[fields[0]] = timestamp < cutoff
+		parents[fields[0]] = fields[2:]
+	}
+
+	seen := make(map[string]bool)
+	var boundary []string
+	add := func(commit string) {
+		if !seen[commit] {
+			seen[commit] = true
+			boundary = append(boundary, commit)
+		}
+	}
+
+	for commit, isOld := range old {
+		if isOld {
+			continue
+		}
+		for _, parent := range parents[commit] {
+			if old[parent] {
+				add(parent)
+			}
+		}
+	}
+	for _, tip := range tips {
+		if old[tip] {
+			add(tip)
+		}
+	}
+
+	if len(boundary) == 0 {
+		fmt.Println("指定日時より前のコミットはありません。")
+	}
+	return boundary, nil
+}
+
+// sortRootCandidates は合成ルートのツリーとして使用する順にコミットを並べ替える
+// 現在のブランチから到達可能なコミットを優先し、その中ではauthor日時の新しい順とする
+func sortRootCandidates(gitDir string, commits []string) ([]string, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", append([]string{"show", "-s", "--format=%H %at"}, commits...)...)
+	if err != nil {
+		return nil, fmt.Errorf("コミット日時取得エラー: %v\nstderr: %s", err, stderr)
+	}
+	timestamps := make(map[string]int64)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		timestamps[fields[0]], _ = strconv.ParseInt(fields[1], 10, 64)
+	}
+
+	onHead := make(map[string]bool)
+	for _, commit := range commits {
+		_, _, err := utils.RunCommand(gitDir, "git", "merge-base", "--is-ancestor", commit, "HEAD")
+		onHead[commit] = err == nil
+	}
+
+	sorted := append([]string(nil), commits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if onHead[sorted[i]] != onHead[sorted[j]] {
+			return onHead[sorted[i]]
+		}
+		return timestamps[sorted[i]] > timestamps[sorted[j]]
+	})
+	return sorted, nil
+}
+
+// refTipCommits は全ブランチ・タグが指すコミットを重複なく返す
+func refTipCommits(gitDir string) ([]string, error) {
+	refs, err := listRefs(gitDir, "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	tags, err := listRefs(gitDir, "refs/tags/")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var commits []string
+	for _, ref := range append(refs, tags...) {
+		stdout, _, err := utils.RunCommand(gitDir, "git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+		if err != nil {
+			// コミット以外を指すタグは対象外
+			continue
+		}
+		commit := strings.TrimSpace(stdout)
+		if !seen[commit] {
+			seen[commit] = true
+			commits = append(commits, commit)
+		}
+	}
+	return commits, nil
+}
+
+// commitParents はコミットの親の一覧を返す
+func commitParents(gitDir, commit string) ([]string, error) {
+	stdout, stderr, err := utils.RunCommand(gitDir, "git", "log", "-1", "--format=%P", commit)
+	if err != nil {
+		return nil, fmt.Errorf("親コミット取得エラー: %s: %v\nstderr: %s", commit, err, stderr)
+	}
+	return strings.Fields(stdout), nil
+}
@@ -0,0 +1,186 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/utils"
+)
+
+// logSubjects はコミットメッセージの一覧を新しい順に取得する
+func logSubjects(t *testing.T, repo, rev string) []string {
+	t.Helper()
+
+	stdout, stderr, err := utils.RunCommand(repo, "git", "log", "--format=%s", rev)
+	if err != nil {
+		t.Fatalf("git log エラー: %v, stderr: %s", err, stderr)
+	}
+	return strings.Split(strings.TrimSpace(stdout), "\n")
+}
+
+// TestRewriteHistorySquash は--squash-historyの動作をテストする
+func TestRewriteHistorySquash(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commitWithDate(t, repo, "second", "2021-01-01T00:00:00Z", "old@corp.example")
+	commitWithDate(t, repo, "third", "2022-01-01T00:00:00Z", "old@corp.example")
+	utils.RunCommand(repo, "git", "tag", "-a", "v1.0", "-m", "release")
+
+	treeBefore, _, _ := utils.RunCommand(repo, "git", "rev-parse", "HEAD^{tree}")
+
+	opts := RewriteOptions{SquashHistory: true}
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", opts); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	subjects := logSubjects(t, repo, "HEAD")
+	if len(subjects) != 1 || subjects[0] != squashedRootMessage {
+		t.Errorf("履歴が1つのルートコミットになっていません: %v", subjects)
+	}
+
+	treeAfter, _, _ := utils.RunCommand(repo, "git", "rev-parse", "HEAD^{tree}")
+	if treeBefore != treeAfter {
+		t.Errorf("ツリーが変更されました: %s → %s", treeBefore, treeAfter)
+	}
+
+	tagSubjects := logSubjects(t, repo, "v1.0")
+	if len(tagSubjects) != 1 {
+		t.Errorf("タグの履歴が畳み込まれていません: %v", tagSubjects)
+	}
+
+	// 一時的なreplace参照が残っていないことを確認
+	if refs, _ := listRefs(repo, "refs/replace/"); len(refs) != 0 {
+		t.Errorf("replace参照が残っています: %v", refs)
+	}
+}
+
+// TestRewriteHistoryTruncate は--truncate-beforeの動作をテストする
+func TestRewriteHistoryTruncate(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commitWithDate(t, repo, "old-1", "2019-01-01T00:00:00Z", "old@corp.example")
+	commitWithDate(t, repo, "old-2", "2019-06-01T00:00:00Z", "old@corp.example")
+	commitWithDate(t, repo, "new-1", "2022-01-01T00:00:00Z", "old@corp.example")
+	commitWithDate(t, repo, "new-2", "2022-06-01T00:00:00Z", "old@corp.example")
+
+	before, _ := ParseDate("2020-01-01T00:00:00Z")
+	opts := RewriteOptions{TruncateBefore: before}
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", opts); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	subjects := logSubjects(t, repo, "HEAD")
+	expected := []string{"new-2", "new-1", "Squashed history before 2020-01-01"}
+	if strings.Join(subjects, ",") != strings.Join(expected, ",") {
+		t.Errorf("期待される履歴: %v, 実際: %v", expected, subjects)
+	}
+
+	// 合成ルートコミットは畳み込み直前のツリーを持つ
+	content, _, _ := utils.RunCommand(repo, "git", "show", "HEAD~2:file.txt")
+	if strings.TrimSpace(content) != "old-2" {
+		t.Errorf("合成ルートコミットのツリーが正しくありません: %q", content)
+	}
+}
+
+// TestRewriteHistoryTruncateMultipleBranches は複数のブランチの古い履歴が1つのルートに畳み込まれることをテストする
+func TestRewriteHistoryTruncateMultipleBranches(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commitWithDate(t, repo, "old-1", "2019-01-01T00:00:00Z", "old@corp.example")
+	commitWithDate(t, repo, "old-2", "2019-06-01T00:00:00Z", "old@corp.example")
+	branch, _, _ := utils.RunCommand(repo, "git", "branch", "--show-current")
+	utils.RunCommand(repo, "git", "checkout", "-q", "-b", "feature")
+	commitWithDate(t, repo, "old-feature", "2019-09-01T00:00:00Z", "old@corp.example")
+	commitWithDate(t, repo, "new-feature", "2022-03-01T00:00:00Z", "old@corp.example")
+	utils.RunCommand(repo, "git", "checkout", "-q", strings.TrimSpace(branch))
+	commitWithDate(t, repo, "new-1", "2022-01-01T00:00:00Z", "old@corp.example")
+
+	before, _ := ParseDate("2020-01-01T00:00:00Z")
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", RewriteOptions{TruncateBefore: before}); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	roots, _, _ := utils.RunCommand(repo, "git", "rev-list", "--max-parents=0", "--branches", "--tags")
+	if lines := strings.Fields(roots); len(lines) != 1 {
+		t.Fatalf("ルートコミットが1つではありません: %v", lines)
+	}
+
+	// 現在のブランチの畳み込み対象（old-2）のツリーから合成ルートを作成し、
+	// ツリーが異なる別のブランチの畳み込み対象（old-feature）は元のツリーとメッセージのままその子になる
+	squashed := "Squashed history before 2020-01-01"
+	if subjects := logSubjects(t, repo, "HEAD"); strings.Join(subjects, ",") != "new-1,"+squashed {
+		t.Errorf("現在のブランチの履歴が正しくありません: %v", subjects)
+	}
+	if subjects := logSubjects(t, repo, "feature"); strings.Join(subjects, ",") != "new-feature,old-feature,"+squashed {
+		t.Errorf("featureブランチの履歴が正しくありません: %v", subjects)
+	}
+	if content, _, _ := utils.RunCommand(repo, "git", "show", "HEAD~1:file.txt"); strings.TrimSpace(content) != "old-2" {
+		t.Errorf("合成ルートコミットのツリーが正しくありません: %q", content)
+	}
+	if content, _, _ := utils.RunCommand(repo, "git", "show", "feature~1:file.txt"); strings.TrimSpace(content) != "old-feature" {
+		t.Errorf("featureブランチの畳み込み対象のツリーが正しくありません: %q", content)
+	}
+
+	if refs, _ := listRefs(repo, "refs/replace/"); len(refs) != 0 {
+		t.Errorf("replace参照が残っています: %v", refs)
+	}
+}
+
+// TestRewriteHistorySquashMultipleBranches は複数のブランチがある場合も合成ルートが1つになることをテストする
+func TestRewriteHistorySquashMultipleBranches(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commitWithDate(t, repo, "second", "2021-01-01T00:00:00Z", "old@corp.example")
+	branch, _, _ := utils.RunCommand(repo, "git", "branch", "--show-current")
+	utils.RunCommand(repo, "git", "checkout", "-q", "-b", "feature")
+	commitWithDate(t, repo, "feature", "2023-01-01T00:00:00Z", "old@corp.example")
+	utils.RunCommand(repo, "git", "checkout", "-q", strings.TrimSpace(branch))
+	commitWithDate(t, repo, "third", "2022-01-01T00:00:00Z", "old@corp.example")
+	utils.RunCommand(repo, "git", "tag", "v1.0")
+	treeBefore, _, _ := utils.RunCommand(repo, "git", "rev-parse", "HEAD^{tree}")
+
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", RewriteOptions{SquashHistory: true}); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	roots, _, _ := utils.RunCommand(repo, "git", "rev-list", "--max-parents=0", "--branches", "--tags")
+	if lines := strings.Fields(roots); len(lines) != 1 {
+		t.Fatalf("ルートコミットが1つではありません: %v", lines)
+	}
+
+	// 合成ルートは現在のブランチのツリーを持ち、同じツリーのタグも合成ルートを指す
+	if subjects := logSubjects(t, repo, "HEAD"); strings.Join(subjects, ",") != squashedRootMessage {
+		t.Errorf("現在のブランチの履歴が正しくありません: %v", subjects)
+	}
+	if treeAfter, _, _ := utils.RunCommand(repo, "git", "rev-parse", "HEAD^{tree}"); treeAfter != treeBefore {
+		t.Errorf("ツリーが変更されました: %s → %s", treeBefore, treeAfter)
+	}
+	head, _, _ := utils.RunCommand(repo, "git", "rev-parse", "HEAD", "v1.0")
+	if lines := strings.Fields(head); len(lines) != 2 || lines[0] != lines[1] {
+		t.Errorf("タグが合成ルートを指していません: %v", lines)
+	}
+
+	// ツリーが異なるブランチの先端は、元のツリーとメッセージのまま合成ルートの子になる
+	if subjects := logSubjects(t, repo, "feature"); strings.Join(subjects, ",") != "feature,"+squashedRootMessage {
+		t.Errorf("featureブランチの履歴が正しくありません: %v", subjects)
+	}
+	if content, _, _ := utils.RunCommand(repo, "git", "show", "feature:file.txt"); strings.TrimSpace(content) != "feature" {
+		t.Errorf("featureブランチのツリーが正しくありません: %q", content)
+	}
+
+	if refs, _ := listRefs(repo, "refs/replace/"); len(refs) != 0 {
+		t.Errorf("replace参照が残っています: %v", refs)
+	}
+}
+
+// TestRewriteHistoryTruncateNoOldCommits は対象となる古いコミットがない場合をテストする
+func TestRewriteHistoryTruncateNoOldCommits(t *testing.T) {
+	repo := setupPreflightRepo(t)
+	commitWithDate(t, repo, "second", "2022-01-01T00:00:00Z", "old@corp.example")
+	countBefore, _ := CountCommits(repo)
+
+	before, _ := ParseDate("2000-01-01")
+	if err := RewriteHistoryWithOptions(repo, "newuser", "new@example.com", RewriteOptions{TruncateBefore: before}); err != nil {
+		t.Fatalf("RewriteHistoryWithOptionsでエラーが発生しました: %v", err)
+	}
+
+	if countAfter, _ := CountCommits(repo); countAfter != countBefore {
+		t.Errorf("コミット数が変化しました: %d → %d", countBefore, countAfter)
+	}
+}
@@ -3,6 +3,7 @@ package github
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"git-rewrite/pkg/utils"
 	"io"
@@ -15,22 +16,63 @@ import (
 // Client はGitHub APIクライアント
 type Client struct {
 	Token      string
+	Host       utils.Host // GitHubのホスト（github.com、GitHub Enterprise Server）
+	BaseURL    string     // APIのベースURL（例: https://api.github.com, https://ghe.corp/api/v3）
 	HTTPClient *http.Client
 }
 
-// NewClient は新しいGitHub APIクライアントを作成する
+// NewClient はgithub.comの新しいGitHub APIクライアントを作成する
 func NewClient(token string) *Client {
+	return NewClientForHost(token, utils.DefaultHost, "")
+}
+
+// NewClientForHost はGitHub Enterprise Serverなど指定したホストのGitHub APIクライアントを作成する
+// APIのベースURLが空の場合はホストから決定する
+func NewClientForHost(token string, host utils.Host, apiURL string) *Client {
+	if apiURL == "" {
+		apiURL = host.GitHubAPIURL()
+	}
 	return &Client{
-		Token: token,
+		Token:   token,
+		Host:    host,
+		BaseURL: strings.TrimSuffix(strings.TrimSpace(apiURL), "/"),
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
+// noreplyEmailDomain はGitHubのnoreplyメールアドレスのドメイン（例: users.noreply.github.com）
+func noreplyEmailDomain(host string) string {
+	if host == "" {
+		host = utils.DefaultGitHubHost
+	}
+	return "users.noreply." + host
+}
+
 // User はGitHubユーザー情報
 type User struct {
+	ID    int64  `json:"id"`
 	Login string `json:"login"`
+	Host  string `json:"-"` // ユーザーのGitHubのホスト（空の場合はgithub.com）
+}
+
+// NoreplyEmail はユーザーのnoreplyメールアドレス（ID+login@users.noreply.github.com）を返す
+// メールアドレスを非公開にしているアカウントでもGH007で拒否されずにプッシュできる
+func (u *User) NoreplyEmail() string {
+	if u.ID == 0 {
+		// ID を含まない旧形式
+		return fmt.Sprintf("%s@%s", u.Login, noreplyEmailDomain(u.Host))
+	}
+	return fmt.Sprintf("%d+%s@%s", u.ID, u.Login, noreplyEmailDomain(u.Host))
+}
+
+// Email はGitHubアカウントに登録されたメールアドレス
+type Email struct {
+	Email      string `json:"email"`
+	Primary    bool   `json:"primary"`
+	Verified   bool   `json:"verified"`
+	Visibility string `json:"visibility"`
 }
 
 // Repository はGitHubリポジトリ情報
@@ -60,7 +102,7 @@ type ActionsPermissions struct {
 
 // CheckRepoExists はリポジトリの存在を確認する
 func (c *Client) CheckRepoExists(owner, repo string) (bool, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s", c.BaseURL, owner, repo)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -93,7 +135,7 @@ func (c *Client) GetCurrentUser() (*User, error) {
 		return nil, fmt.Errorf("GitHub トークンが設定されていません")
 	}
 
-	url := "https://api.github.com/user"
+	url := c.BaseURL + "/user"
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -122,10 +164,88 @@ func (c *Client) GetCurrentUser() (*User, error) {
 	if err := json.Unmarshal(body, &user); err != nil {
 		return nil, err
 	}
+	user.Host = c.Host.Hostname()
 
 	return &user, nil
 }
 
+// ErrMissingEmailScope はトークンにメールアドレス一覧の取得権限がないことを表す
+var ErrMissingEmailScope = errors.New("トークンに 'user:email' スコープが付与されていません")
+
+// GetUserEmails は現在のユーザーに登録されたメールアドレスの一覧を取得する
+// トークンに user:email スコープが必要
+func (c *Client) GetUserEmails() ([]Email, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("GitHub トークンが設定されていません")
+	}
+
+	url := c.BaseURL + "/user/emails"
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "token "+c.Token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 403 || resp.StatusCode == 404 {
+		return nil, fmt.Errorf("メールアドレス一覧取得エラー: %d - %s: %w", resp.StatusCode, resp.Status, ErrMissingEmailScope)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("メールアドレス一覧取得エラー: %d - %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var emails []Email
+	if err := json.Unmarshal(body, &emails); err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+// VerifyAccountEmail はメールアドレスが現在のユーザーに登録され、確認済みであることを検証する
+// noreplyメールアドレスは/user/emailsに含まれないため、ユーザーIDとログイン名から照合する
+func (c *Client) VerifyAccountEmail(email string) error {
+	if strings.HasSuffix(strings.ToLower(email), "@"+noreplyEmailDomain(c.Host.Hostname())) {
+		user, err := c.GetCurrentUser()
+		if err != nil {
+			return err
+		}
+		legacy := User{Login: user.Login, Host: user.Host}
+		if !strings.EqualFold(email, user.NoreplyEmail()) && !strings.EqualFold(email, legacy.NoreplyEmail()) {
+			return fmt.Errorf("%s は %s のnoreplyメールアドレスではありません（正しいアドレス: %s）", email, user.Login, user.NoreplyEmail())
+		}
+		return nil
+	}
+
+	emails, err := c.GetUserEmails()
+	if err != nil {
+		return err
+	}
+	for _, registered := range emails {
+		if !strings.EqualFold(registered.Email, email) {
+			continue
+		}
+		if !registered.Verified {
+			return fmt.Errorf("%s はGitHubアカウントに登録されていますが、確認されていません", email)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s はGitHubアカウントに登録されていません（コミットがアカウントに関連付けられません）", email)
+}
+
 // IsOrganization は指定されたオーナーが組織かどうかを判定する
 func (c *Client) IsOrganization(owner string) (bool, error) {
 	if c.Token == "" {
@@ -133,7 +253,7 @@ func (c *Client) IsOrganization(owner string) (bool, error) {
 	}
 
 	// まず組織として確認
-	url := fmt.Sprintf("https://api.github.com/orgs/%s", owner)
+	url := fmt.Sprintf("%s/orgs/%s", c.BaseURL, owner)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return false, err
@@ -174,11 +294,11 @@ func (c *Client) CreateRepo(owner, repo string, private bool) error {
 
 	// 個人リポジトリ所有者が設定されている場合は個人リポジトリとして扱う
 	if utils.IsPersonalRepository(os.Getenv("GITHUB_REPOSITORY_OWNER")) {
-		url = "https://api.github.com/user/repos"
+		url = c.BaseURL + "/user/repos"
 		fmt.Printf("個人リポジトリとして作成します（個人リポジトリ所有者指定）: %s\n", owner)
 	} else if owner == currentUser.Login {
 		// 現在のユーザーと同じ場合は個人リポジトリ
-		url = "https://api.github.com/user/repos"
+		url = c.BaseURL + "/user/repos"
 		fmt.Printf("個人リポジトリとして作成します: %s\n", owner)
 	} else {
 		// 異なる場合は組織かどうかを確認
@@ -192,7 +312,7 @@ func (c *Client) CreateRepo(owner, repo string, private bool) error {
 
 		if isOrg {
 			// 組織リポジトリ
-			url = fmt.Sprintf("https://api.github.com/orgs/%s/repos", owner)
+			url = fmt.Sprintf("%s/orgs/%s/repos", c.BaseURL, owner)
 			fmt.Printf("組織リポジトリとして作成します: %s\n", owner)
 		} else {
 			// 個人ユーザーのリポジトリ（他のユーザー）
@@ -264,7 +384,7 @@ func (c *Client) AddCollaborator(owner, repo, username, permission string) error
 		return fmt.Errorf("GitHub トークンが設定されていません")
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/collaborators/%s", owner, repo, username)
+	url := fmt.Sprintf("%s/repos/%s/%s/collaborators/%s", c.BaseURL, owner, repo, username)
 
 	data := map[string]string{
 		"permission": permission,
@@ -358,6 +478,11 @@ func LoadCollaboratorConfig(configPath string) (*CollaboratorConfig, error) {
 
 // GetCollaborators は複数のソースからコラボレーター情報を取得する
 func (c *Client) GetCollaborators(configPath, repoName string) []Collaborator {
+	return ResolveCollaborators(configPath, repoName)
+}
+
+// ResolveCollaborators は設定ファイルと環境変数からリポジトリのコラボレーター情報を取得する
+func ResolveCollaborators(configPath, repoName string) []Collaborator {
 	var collaborators []Collaborator
 
 	// 1. 設定ファイルから読み込み（優先度: 高）
@@ -467,7 +592,7 @@ func (c *Client) SetActionsEnabled(owner, repo string, enabled bool) error {
 		return fmt.Errorf("GitHub トークンが設定されていません")
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/actions/permissions", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/permissions", c.BaseURL, owner, repo)
 
 	permissions := ActionsPermissions{
 		Enabled: enabled,
@@ -509,7 +634,7 @@ func (c *Client) GetActionsEnabled(owner, repo string) (bool, error) {
 		return false, fmt.Errorf("GitHub トークンが設定されていません")
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/actions/permissions", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/permissions", c.BaseURL, owner, repo)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
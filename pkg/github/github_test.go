@@ -1,8 +1,15 @@
 package github
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
+
+	"git-rewrite/pkg/utils"
 )
 
 func TestParseCollaboratorsFromEnv(t *testing.T) {
@@ -219,3 +226,172 @@ func TestCreateRepoWithCollaboratorsErrorHandling(t *testing.T) {
 		t.Errorf("期待されるエラーメッセージ: %s, 実際: %s", expectedError, err.Error())
 	}
 }
+
+// TestUserNoreplyEmail はnoreplyメールアドレスの生成をテストする
+func TestUserNoreplyEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     User
+		expected string
+	}{
+		{
+			name:     "IDを含む形式",
+			user:     User{ID: 1234567, Login: "octocat"},
+			expected: "1234567+octocat@users.noreply.github.com",
+		},
+		{
+			name:     "IDがない場合は旧形式",
+			user:     User{Login: "octocat"},
+			expected: "octocat@users.noreply.github.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.user.NoreplyEmail(); actual != tt.expected {
+				t.Errorf("期待値: %s, 実際: %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+// rewriteTransport はGitHub APIへのリクエストをテスト用サーバーに転送する
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestClient はテスト用サーバーに接続するClientを作成する
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, _ := url.Parse(server.URL)
+	client := NewClient("ghp_test123")
+	client.HTTPClient.Transport = &rewriteTransport{target: target}
+	return client
+}
+
+// TestVerifyAccountEmail はメールアドレスの登録確認をテストする
+func TestVerifyAccountEmail(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"email": "me@example.com", "primary": true, "verified": true, "visibility": "private"},
+			{"email": "old@example.com", "primary": false, "verified": false, "visibility": null}
+		]`))
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 1234567, "login": "octocat"}`))
+	})
+	client := newTestClient(t, mux)
+
+	tests := []struct {
+		name        string
+		email       string
+		shouldError bool
+	}{
+		{name: "登録・確認済み", email: "me@example.com", shouldError: false},
+		{name: "大文字小文字の違い", email: "Me@Example.com", shouldError: false},
+		{name: "未確認", email: "old@example.com", shouldError: true},
+		{name: "未登録", email: "other@example.com", shouldError: true},
+		{name: "自分のnoreplyアドレス", email: "1234567+octocat@users.noreply.github.com", shouldError: false},
+		{name: "旧形式のnoreplyアドレス", email: "octocat@users.noreply.github.com", shouldError: false},
+		{name: "他人のnoreplyアドレス", email: "7654321+someone@users.noreply.github.com", shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.VerifyAccountEmail(tt.email)
+			if tt.shouldError && err == nil {
+				t.Errorf("%s でエラーが期待されましたが、エラーが発生しませんでした", tt.email)
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("%s でエラーが期待されませんでしたが、エラーが発生しました: %v", tt.email, err)
+			}
+		})
+	}
+}
+
+// TestGetUserEmailsMissingScope はスコープ不足の場合のエラーをテストする
+func TestGetUserEmailsMissingScope(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	if _, err := client.GetUserEmails(); !errors.Is(err, ErrMissingEmailScope) {
+		t.Errorf("スコープ不足のエラーが期待されました: %v", err)
+	}
+}
+
+// TestRestoreCIState は元々無効だったGitHub Actionsもプッシュ後に有効化されることをテストする
+func TestRestoreCIState(t *testing.T) {
+	enabled := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/corp/app/actions/permissions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			var permissions ActionsPermissions
+			json.NewDecoder(r.Body).Decode(&permissions)
+			enabled = permissions.Enabled
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(ActionsPermissions{Enabled: enabled})
+	})
+	client := newTestClient(t, mux)
+
+	state, err := client.CIState("corp", "app")
+	if err != nil || state != "false" {
+		t.Fatalf("GitHub Actionsの状態が取得できません: %s, %v", state, err)
+	}
+	if err := client.DisableCI("corp", "app"); err != nil {
+		t.Fatalf("DisableCIでエラーが発生しました: %v", err)
+	}
+	if err := client.RestoreCIState("corp", "app", state); err != nil {
+		t.Fatalf("RestoreCIStateでエラーが発生しました: %v", err)
+	}
+	if !enabled {
+		t.Error("GitHub Actionsが有効化されていません")
+	}
+}
+
+// TestClientEnterpriseBaseURL はGitHub Enterprise ServerのAPIのベースURLにリクエストすることをテストする
+func TestClientEnterpriseBaseURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 7, "login": "corp-user"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := NewClientForHost("ghp_test123", utils.NewHost("ghe.corp", false), server.URL+"/api/v3/")
+	if client.BaseURL != server.URL+"/api/v3" {
+		t.Fatalf("BaseURLが期待値と異なります: %s", client.BaseURL)
+	}
+
+	user, err := client.GetCurrentUser()
+	if err != nil {
+		t.Fatalf("GetCurrentUserでエラーが発生しました: %v", err)
+	}
+	if user.Login != "corp-user" {
+		t.Errorf("ユーザー情報が期待値と異なります: %+v", user)
+	}
+	if email := user.NoreplyEmail(); email != "7+corp-user@users.noreply.ghe.corp" {
+		t.Errorf("noreplyメールアドレスが期待値と異なります: %s", email)
+	}
+
+	pushURL, err := client.PushURL("git@ghe.corp:team/repo.git")
+	if err != nil || pushURL != "https://ghp_test123@ghe.corp/team/repo.git" {
+		t.Errorf("トークン付きURLが期待値と異なります: %s, %v", pushURL, err)
+	}
+	if _, err := client.PushURL("https://github.com/team/repo.git"); err == nil {
+		t.Error("クライアントのホスト以外のURLでエラーが期待されました")
+	}
+}
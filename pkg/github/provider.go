@@ -0,0 +1,49 @@
+package github
+
+import "strconv"
+
+// 以下はrewriter.HostingProviderの実装
+// GitHub固有のAPI（Actions・コラボレーター）をホスティングサービス共通の操作として提供する
+
+// Name はホスティングサービスの表示名を返す
+func (c *Client) Name() string {
+	return "GitHub"
+}
+
+// RepoExists はリポジトリの存在を確認する
+func (c *Client) RepoExists(owner, repo string) (bool, error) {
+	return c.CheckRepoExists(owner, repo)
+}
+
+// GrantAccess はユーザーをコラボレーターとして追加する
+func (c *Client) GrantAccess(owner, repo, username, permission string) error {
+	return c.AddCollaborator(owner, repo, username, permission)
+}
+
+// CIState はGitHub Actionsの有効/無効を "true" / "false" で返す
+func (c *Client) CIState(owner, repo string) (string, error) {
+	enabled, err := c.GetActionsEnabled(owner, repo)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatBool(enabled), nil
+}
+
+// DisableCI はGitHub Actionsを無効にする
+func (c *Client) DisableCI(owner, repo string) error {
+	return c.SetActionsEnabled(owner, repo, false)
+}
+
+// RestoreCIState はGitHub Actionsを有効にする
+// 元々無効だった場合も、GitHubのデフォルト状態である有効に戻す
+func (c *Client) RestoreCIState(owner, repo, state string) error {
+	return c.SetActionsEnabled(owner, repo, true)
+}
+
+// PushURL はトークン付きHTTPS URLを返す（トークンが空の場合はremoteURLをそのまま返す）
+func (c *Client) PushURL(remoteURL string) (string, error) {
+	if c.Token == "" {
+		return remoteURL, nil
+	}
+	return c.Host.TokenURL(remoteURL, c.Token, "")
+}
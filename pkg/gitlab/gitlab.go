@@ -0,0 +1,290 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"git-rewrite/pkg/utils"
+)
+
+// GitLabのアクセスレベル
+const (
+	reporterAccess   = 20
+	developerAccess  = 30
+	maintainerAccess = 40
+)
+
+// accessLevels はgithub.Collaboratorの権限に対応するGitLabのアクセスレベル
+// プロジェクトのOwnerはプロジェクトのメンバーとして付与できないバージョンがあるため、adminはMaintainerとする
+var accessLevels = map[string]int{
+	"pull":     reporterAccess,
+	"triage":   reporterAccess,
+	"push":     developerAccess,
+	"maintain": maintainerAccess,
+	"admin":    maintainerAccess,
+}
+
+// buildsDisabled はCI/CDを無効にするbuilds_access_levelの値（他に enabled, private がある）
+const buildsDisabled = "disabled"
+
+// Client はGitLab APIクライアント
+type Client struct {
+	Token      string
+	Host       utils.Host // GitLabのホスト（例: gitlab.example.com）
+	BaseURL    string     // APIのベースURL（例: https://gitlab.example.com/api/v4）
+	HTTPClient *http.Client
+}
+
+// NewClient は新しいGitLab APIクライアントを作成する
+// APIのベースURLが空の場合はホストから決定する
+func NewClient(token string, host utils.Host, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL(host.Name)
+	}
+	host.Nested = true
+	return &Client{
+		Token:   token,
+		Host:    host,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// DefaultAPIURL はホストのGitLab REST APIのベースURLを返す
+func DefaultAPIURL(host string) string {
+	return "https://" + host + "/api/v4"
+}
+
+// namespace はGitLabのネームスペース（ユーザーまたはグループ・サブグループ）
+type namespace struct {
+	ID       int64  `json:"id"`
+	Kind     string `json:"kind"`
+	FullPath string `json:"full_path"`
+}
+
+// project はGitLabのプロジェクト情報
+type project struct {
+	ID                int64  `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	BuildsAccessLevel string `json:"builds_access_level"`
+}
+
+// user はGitLabのユーザー情報
+type user struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// projectID はAPIのパスに使用するプロジェクトのID（URLエンコードしたフルパス）を返す
+func projectID(owner, repo string) string {
+	return url.PathEscape(owner + "/" + repo)
+}
+
+// request はAPIリクエストを送信し、ステータスコードとレスポンスボディを返す
+func (c *Client) request(method, path string, payload interface{}) (int, []byte, error) {
+	if c.Token == "" {
+		return 0, nil, fmt.Errorf("GitLab トークンが設定されていません")
+	}
+
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, err
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+// Name はホスティングサービスの表示名を返す
+func (c *Client) Name() string {
+	return "GitLab"
+}
+
+// RepoExists はプロジェクトの存在を確認する
+func (c *Client) RepoExists(owner, repo string) (bool, error) {
+	status, body, err := c.request("GET", "/projects/"+projectID(owner, repo), nil)
+	if err != nil {
+		return false, err
+	}
+	switch status {
+	case 200:
+		return true, nil
+	case 404:
+		return false, nil
+	}
+	return false, fmt.Errorf("プロジェクト確認エラー: %d\n詳細: %s", status, string(body))
+}
+
+// CreateRepo はグループ（サブグループを含む）またはユーザーのネームスペースにプロジェクトを作成する
+func (c *Client) CreateRepo(owner, repo string, private bool) error {
+	// ネームスペースのIDを取得
+	status, body, err := c.request("GET", "/namespaces/"+url.PathEscape(owner), nil)
+	if err != nil {
+		return err
+	}
+	if status == 404 {
+		return fmt.Errorf("ネームスペース %s が見つかりません。グループ・サブグループを作成してから再実行してください", owner)
+	}
+	if status != 200 {
+		return fmt.Errorf("ネームスペース取得エラー: %d\n詳細: %s", status, string(body))
+	}
+	var ns namespace
+	if err := json.Unmarshal(body, &ns); err != nil {
+		return err
+	}
+
+	visibility := "public"
+	if private {
+		visibility = "private"
+	}
+	data := map[string]interface{}{
+		"name":         repo,
+		"path":         repo,
+		"namespace_id": ns.ID,
+		"visibility":   visibility,
+		"description":  fmt.Sprintf("Repository created automatically for %s", repo),
+	}
+	status, body, err = c.request("POST", "/projects", data)
+	if err != nil {
+		return err
+	}
+
+	if status == 201 {
+		fmt.Printf("✅ GitLabプロジェクト %s/%s を作成しました（%s: %s）。\n", owner, repo, ns.Kind, visibility)
+		return nil
+	}
+	// 同名のプロジェクトが既に存在する場合は400で "has already been taken" が返る
+	if status == 400 && strings.Contains(string(body), "has already been taken") {
+		fmt.Printf("⚠️  プロジェクト %s/%s は既に存在します。既存のプロジェクトを使用します。\n", owner, repo)
+		return nil
+	}
+	return fmt.Errorf("プロジェクト作成エラー: %d\n詳細: %s", status, string(body))
+}
+
+// GrantAccess はユーザーをプロジェクトのメンバーとして追加する
+// permissionはgithub.Collaboratorの権限（pull, triage, push, maintain, admin）で指定する
+func (c *Client) GrantAccess(owner, repo, username, permission string) error {
+	level, ok := accessLevels[permission]
+	if !ok {
+		return fmt.Errorf("GitLabのアクセスレベルに対応しない権限です: %s", permission)
+	}
+
+	// ユーザー名からユーザーIDを取得
+	status, body, err := c.request("GET", "/users?username="+url.QueryEscape(username), nil)
+	if err != nil {
+		return err
+	}
+	if status != 200 {
+		return fmt.Errorf("ユーザー取得エラー: %d\n詳細: %s", status, string(body))
+	}
+	var users []user
+	if err := json.Unmarshal(body, &users); err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return fmt.Errorf("ユーザー %s が見つかりません", username)
+	}
+
+	membersPath := "/projects/" + projectID(owner, repo) + "/members"
+	status, body, err = c.request("POST", membersPath, map[string]interface{}{
+		"user_id":      users[0].ID,
+		"access_level": level,
+	})
+	if err != nil {
+		return err
+	}
+	if status == 409 {
+		// 既にメンバーの場合はアクセスレベルを更新する
+		status, body, err = c.request("PUT", fmt.Sprintf("%s/%d", membersPath, users[0].ID), map[string]interface{}{
+			"access_level": level,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if status == 200 || status == 201 {
+		fmt.Printf("✅ メンバー %s を %s/%s に追加しました（権限: %s → アクセスレベル %d）\n", username, owner, repo, permission, level)
+		return nil
+	}
+	return fmt.Errorf("メンバー追加エラー: %d\n詳細: %s", status, string(body))
+}
+
+// CIState はプロジェクトのCI/CDパイプラインのアクセスレベル（enabled, private, disabled）を返す
+func (c *Client) CIState(owner, repo string) (string, error) {
+	status, body, err := c.request("GET", "/projects/"+projectID(owner, repo), nil)
+	if err != nil {
+		return "", err
+	}
+	if status != 200 {
+		return "", fmt.Errorf("CI/CD状態取得エラー: %d\n詳細: %s", status, string(body))
+	}
+	var p project
+	if err := json.Unmarshal(body, &p); err != nil {
+		return "", err
+	}
+	return p.BuildsAccessLevel, nil
+}
+
+// DisableCI はプロジェクトのCI/CDパイプラインを無効にする
+func (c *Client) DisableCI(owner, repo string) error {
+	return c.setBuildsAccessLevel(owner, repo, buildsDisabled)
+}
+
+// RestoreCIState はプロジェクトのCI/CDパイプラインのアクセスレベルをCIStateで取得した値に戻す
+func (c *Client) RestoreCIState(owner, repo, state string) error {
+	return c.setBuildsAccessLevel(owner, repo, state)
+}
+
+// setBuildsAccessLevel はプロジェクトのCI/CDパイプラインのアクセスレベルを設定する
+func (c *Client) setBuildsAccessLevel(owner, repo, level string) error {
+	status, body, err := c.request("PUT", "/projects/"+projectID(owner, repo), map[string]string{
+		"builds_access_level": level,
+	})
+	if err != nil {
+		return err
+	}
+	if status == 200 {
+		fmt.Printf("✅ プロジェクト %s/%s のCI/CDパイプラインのアクセスレベルを%sにしました\n", owner, repo, level)
+		return nil
+	}
+	return fmt.Errorf("CI/CD設定エラー: %d\n詳細: %s", status, string(body))
+}
+
+// PushURL はトークン付きHTTPS URL（https://oauth2:<token>@host/group/repo.git）を返す
+// トークンが空の場合はremoteURLをそのまま返す
+func (c *Client) PushURL(remoteURL string) (string, error) {
+	if c.Token == "" {
+		return remoteURL, nil
+	}
+	return c.Host.TokenURL(remoteURL, "oauth2", c.Token)
+}
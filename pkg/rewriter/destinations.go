@@ -0,0 +1,143 @@
+package rewriter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"git-rewrite/pkg/git"
+	"git-rewrite/pkg/utils"
+)
+
+// 書き換え先の衝突時の処理方針
+const (
+	CollisionError  = "error"  // 何も書き換えずに中止する
+	CollisionSkip   = "skip"   // 衝突したリポジトリを処理しない
+	CollisionSuffix = "suffix" // 2つ目以降のリポジトリ名に -2, -3 ... を付ける
+)
+
+// Destination はリポジトリの書き換え先（プッシュ先のオーナーとリポジトリ名）を表す
+type Destination struct {
+	GitDir  string
+	Owner   string
+	Repo    string
+	Current bool // リモートが既にこの書き換え先を指しているかどうか
+}
+
+// key は衝突判定に使用するキーを返す（GitHubのオーナー名・リポジトリ名は大文字・小文字を区別しない）
+func (d *Destination) key() string {
+	return strings.ToLower(d.Owner + "/" + d.Repo)
+}
+
+// String は書き換え先を owner/repo の形式で返す
+func (d *Destination) String() string {
+	return d.Owner + "/" + d.Repo
+}
+
+// PlanDestination はリポジトリを変更せずに書き換え先を求める
+// 書き換え先を決定できない場合（リモートがない・書き換え対象のホストのURLではない）はnilを返す
+func (r *Rewriter) PlanDestination(gitDir string) (*Destination, error) {
+	remote, ok, err := git.PlanRemoteURL(gitDir, r.Remote, r.Hosts, r.GitHubUser, r.Owner, r.Organization, r.RepoNamer, r.CreateMissingRemote)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	destination := &Destination{GitDir: gitDir, Owner: remote.Owner, Repo: remote.Repo}
+	if r.RepoNameOverride != "" {
+		destination.Repo = r.RepoNameOverride
+	}
+	if stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", r.Remote); err == nil {
+		if current, err := r.Hosts.Destination.ParseRemoteURL(strings.TrimSpace(stdout)); err == nil {
+			destination.Current = current.HasOwner(destination.Owner) && strings.EqualFold(current.Repo, destination.Repo)
+		}
+	}
+	return destination, nil
+}
+
+// DestinationCollision は同じ書き換え先を持つリポジトリの組を表す
+type DestinationCollision struct {
+	Destination  string
+	Destinations []*Destination
+}
+
+// FindCollisions は同じ書き換え先を持つリポジトリを検出する
+// 各組の中では、リモートが既に書き換え先を指しているリポジトリを先頭にする
+func FindCollisions(destinations []*Destination) []DestinationCollision {
+	groups := make(map[string][]*Destination)
+	var keys []string
+	for _, destination := range destinations {
+		key := destination.key()
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], destination)
+	}
+
+	var collisions []DestinationCollision
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Current && !group[j].Current
+		})
+		collisions = append(collisions, DestinationCollision{Destination: group[0].String(), Destinations: group})
+	}
+	return collisions
+}
+
+// ResolveCollisions は処理方針に従って書き換え先の衝突を解決する
+// 処理しないリポジトリと、リポジトリ名を変更するリポジトリ（GitDir → 新しいリポジトリ名）を返す
+// CollisionErrorの場合、衝突があればエラーを返す
+func ResolveCollisions(destinations []*Destination, strategy string) (map[string]bool, map[string]string, error) {
+	collisions := FindCollisions(destinations)
+	skipped := make(map[string]bool)
+	renamed := make(map[string]string)
+	if len(collisions) == 0 {
+		return skipped, renamed, nil
+	}
+
+	switch strategy {
+	case CollisionError:
+		var lines []string
+		for _, collision := range collisions {
+			var dirs []string
+			for _, destination := range collision.Destinations {
+				dirs = append(dirs, destination.GitDir)
+			}
+			lines = append(lines, fmt.Sprintf("  %s: %s", collision.Destination, strings.Join(dirs, ", ")))
+		}
+		return nil, nil, fmt.Errorf("書き換え先が同じリポジトリがあります:\n%s", strings.Join(lines, "\n"))
+
+	case CollisionSkip:
+		for _, collision := range collisions {
+			for _, destination := range collision.Destinations {
+				skipped[destination.GitDir] = true
+			}
+		}
+
+	case CollisionSuffix:
+		used := make(map[string]bool)
+		for _, destination := range destinations {
+			used[destination.key()] = true
+		}
+		for _, collision := range collisions {
+			// 先頭のリポジトリは元の名前のままにする
+			for _, destination := range collision.Destinations[1:] {
+				for n := 2; ; n++ {
+					candidate := &Destination{Owner: destination.Owner, Repo: fmt.Sprintf("%s-%d", destination.Repo, n)}
+					if !used[candidate.key()] {
+						used[candidate.key()] = true
+						renamed[destination.GitDir] = candidate.Repo
+						break
+					}
+				}
+			}
+		}
+
+	default:
+		return nil, nil, fmt.Errorf("不明な衝突時の処理方針です: %s", strategy)
+	}
+	return skipped, renamed, nil
+}
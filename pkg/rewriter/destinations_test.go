@@ -0,0 +1,78 @@
+package rewriter
+
+import (
+	"testing"
+)
+
+// TestFindCollisions は大文字・小文字を区別せずに書き換え先の衝突を検出することをテストする
+func TestFindCollisions(t *testing.T) {
+	destinations := []*Destination{
+		{GitDir: "/work/a/tools", Owner: "neworg", Repo: "tools"},
+		{GitDir: "/work/app", Owner: "neworg", Repo: "app"},
+		{GitDir: "/work/b/tools", Owner: "NewOrg", Repo: "Tools", Current: true},
+	}
+
+	collisions := FindCollisions(destinations)
+	if len(collisions) != 1 {
+		t.Fatalf("衝突が1件検出されることが期待されました: %+v", collisions)
+	}
+	group := collisions[0].Destinations
+	if len(group) != 2 {
+		t.Fatalf("衝突したリポジトリが2件であることが期待されました: %+v", group)
+	}
+	// remoteが既に書き換え先を指しているリポジトリを先頭にする
+	if group[0].GitDir != "/work/b/tools" {
+		t.Errorf("書き換え先を指しているリポジトリが先頭ではありません: %s", group[0].GitDir)
+	}
+}
+
+// TestResolveCollisions は処理方針ごとの衝突の解決をテストする
+func TestResolveCollisions(t *testing.T) {
+	destinations := []*Destination{
+		{GitDir: "/work/a/tools", Owner: "neworg", Repo: "tools"},
+		{GitDir: "/work/b/tools", Owner: "neworg", Repo: "tools"},
+		{GitDir: "/work/c/tools", Owner: "neworg", Repo: "tools"},
+		{GitDir: "/work/tools-2", Owner: "neworg", Repo: "tools-2"},
+		{GitDir: "/work/app", Owner: "neworg", Repo: "app"},
+	}
+
+	t.Run("error", func(t *testing.T) {
+		if _, _, err := ResolveCollisions(destinations, CollisionError); err == nil {
+			t.Error("衝突がある場合にエラーが期待されました")
+		}
+		if _, _, err := ResolveCollisions(destinations[3:], CollisionError); err != nil {
+			t.Errorf("衝突がない場合はエラーにならないことが期待されました: %v", err)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		skipped, renamed, err := ResolveCollisions(destinations, CollisionSkip)
+		if err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+		if len(skipped) != 3 || !skipped["/work/a/tools"] || skipped["/work/app"] || len(renamed) != 0 {
+			t.Errorf("衝突したリポジトリのみスキップされることが期待されました: %v, %v", skipped, renamed)
+		}
+	})
+
+	t.Run("suffix", func(t *testing.T) {
+		skipped, renamed, err := ResolveCollisions(destinations, CollisionSuffix)
+		if err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+		// tools-2 は既にバッチ内で使用されているため使用しない
+		expected := map[string]string{"/work/b/tools": "tools-3", "/work/c/tools": "tools-4"}
+		if len(skipped) != 0 || len(renamed) != len(expected) {
+			t.Fatalf("期待値 %v, 実際 %v (スキップ: %v)", expected, renamed, skipped)
+		}
+		for gitDir, repo := range expected {
+			if renamed[gitDir] != repo {
+				t.Errorf("%s: 期待値 %s, 実際 %s", gitDir, repo, renamed[gitDir])
+			}
+		}
+	})
+
+	if _, _, err := ResolveCollisions(destinations, "unknown"); err == nil {
+		t.Error("不明な処理方針でエラーが期待されました")
+	}
+}
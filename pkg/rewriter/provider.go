@@ -0,0 +1,51 @@
+package rewriter
+
+import (
+	"fmt"
+
+	"git-rewrite/pkg/git"
+	"git-rewrite/pkg/github"
+)
+
+// HostingProvider は書き換え先のホスティングサービス（GitHubなど）の操作を表す
+// github.Clientが実装する。テストではメモリ上のフェイクに差し替えられる
+type HostingProvider interface {
+	git.PushURLProvider
+
+	// Name はホスティングサービスの表示名を返す
+	Name() string
+	// RepoExists はリポジトリの存在を確認する
+	RepoExists(owner, repo string) (bool, error)
+	// CreateRepo はリポジトリを作成する（既に存在する場合はエラーにしない）
+	CreateRepo(owner, repo string, private bool) error
+	// GrantAccess はユーザーにリポジトリへのアクセス権を付与する（permissionはgithub.Collaboratorの権限）
+	GrantAccess(owner, repo, username, permission string) error
+	// CIState はCIの現在の設定を返す（RestoreCIStateで元に戻すため、サービス固有の値をそのまま返す）
+	CIState(owner, repo string) (string, error)
+	// DisableCI はCIを無効にする
+	DisableCI(owner, repo string) error
+	// RestoreCIState はプッシュ後にCIを戻す（stateはCIStateで取得した値）
+	RestoreCIState(owner, repo, state string) error
+}
+
+// grantAccess はコラボレーターにアクセス権を付与する
+// 一部のユーザーで失敗しても処理を続ける
+func grantAccess(provider HostingProvider, owner, repo string, collaborators []github.Collaborator) {
+	if len(collaborators) == 0 {
+		fmt.Println("ℹ️  コラボレーターの設定が見つかりませんでした")
+		return
+	}
+
+	fmt.Printf("📝 %d 人のコラボレーターを追加しています...\n", len(collaborators))
+
+	successCount := 0
+	for _, collaborator := range collaborators {
+		if err := provider.GrantAccess(owner, repo, collaborator.Username, collaborator.Permission); err != nil {
+			fmt.Printf("⚠️  コラボレーター %s の追加に失敗しました: %v\n", collaborator.Username, err)
+		} else {
+			successCount++
+		}
+	}
+
+	fmt.Printf("✅ %d/%d 人のコラボレーターを正常に追加しました\n", successCount, len(collaborators))
+}
@@ -0,0 +1,208 @@
+package rewriter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-rewrite/pkg/git"
+	"git-rewrite/pkg/utils"
+)
+
+// fakeProvider はメモリ上で動作するテスト用のHostingProvider
+// プッシュ先はローカルのbareリポジトリに差し替える（hostが設定されている場合はそのホストのURLのみ受け付ける）
+type fakeProvider struct {
+	pushDir  string
+	host     *utils.Host
+	repos    map[string]bool
+	grants   []string
+	ciState  string   // CIの現在の設定（空の場合は "enabled"）
+	ciStates []string // 設定したCIの状態の履歴
+}
+
+func (f *fakeProvider) Name() string { return "Fake" }
+
+func (f *fakeProvider) RepoExists(owner, repo string) (bool, error) {
+	return f.repos[owner+"/"+repo], nil
+}
+
+func (f *fakeProvider) CreateRepo(owner, repo string, private bool) error {
+	f.repos[owner+"/"+repo] = true
+	return nil
+}
+
+func (f *fakeProvider) GrantAccess(owner, repo, username, permission string) error {
+	f.grants = append(f.grants, username+":"+permission)
+	return nil
+}
+
+func (f *fakeProvider) CIState(owner, repo string) (string, error) {
+	if f.ciState == "" {
+		return "enabled", nil
+	}
+	return f.ciState, nil
+}
+
+func (f *fakeProvider) DisableCI(owner, repo string) error {
+	f.ciStates = append(f.ciStates, "disabled")
+	return nil
+}
+
+func (f *fakeProvider) RestoreCIState(owner, repo, state string) error {
+	f.ciStates = append(f.ciStates, state)
+	return nil
+}
+
+func (f *fakeProvider) PushURL(remoteURL string) (string, error) {
+	if f.host != nil {
+		if _, err := f.host.ParseRemoteURL(remoteURL); err != nil {
+			return "", err
+		}
+	}
+	return f.pushDir, nil
+}
+
+// runGit はテスト用にGitコマンドを実行する
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	stdout, stderr, err := utils.RunCommand(dir, "git", args...)
+	if err != nil {
+		t.Fatalf("git %s エラー: %v, stderr: %s", strings.Join(args, " "), err, stderr)
+	}
+	return strings.TrimSpace(stdout)
+}
+
+// TestProcessRepositoryWithFakeProvider はフェイクのHostingProviderで書き換えからプッシュまでの処理をテストする
+func TestProcessRepositoryWithFakeProvider(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+	t.Setenv("GITHUB_COLLABORATORS", "")
+
+	repo := filepath.Join(t.TempDir(), "app")
+	runGit(t, filepath.Dir(repo), "init", repo)
+	runGit(t, repo, "-c", "user.name=Old", "-c", "user.email=old@example.com", "commit", "--allow-empty", "-m", "initial")
+	runGit(t, repo, "remote", "add", "origin", "https://github.com/olduser/app.git")
+
+	pushDir := filepath.Join(t.TempDir(), "pushed.git")
+	runGit(t, filepath.Dir(pushDir), "init", "--bare", pushDir)
+
+	provider := &fakeProvider{pushDir: pushDir, repos: make(map[string]bool)}
+	r := NewRewriter("", "newuser", "new@example.com")
+	r.SetProvider(provider)
+	r.SetOwnershipConfig("", "neworg")
+	r.SetCollaboratorsFromString("alice:push")
+
+	result := r.ProcessRepository(repo)
+	if !result.Success {
+		t.Fatalf("処理が成功することが期待されました: %v", result.Error)
+	}
+
+	if !provider.repos["neworg/app"] {
+		t.Errorf("書き換え先のリポジトリが作成されていません: %v", provider.repos)
+	}
+	if len(provider.grants) != 1 || provider.grants[0] != "alice:push" {
+		t.Errorf("コラボレーターにアクセス権が付与されていません: %v", provider.grants)
+	}
+	if strings.Join(provider.ciStates, ",") != "disabled,enabled" {
+		t.Errorf("プッシュ中にCIが無効化・復元されていません: %v", provider.ciStates)
+	}
+	if url := runGit(t, repo, "remote", "get-url", "origin"); url != "https://github.com/neworg/app" {
+		t.Errorf("remote URLが変更されていません: %s", url)
+	}
+
+	branch := runGit(t, repo, "branch", "--show-current")
+	if author := runGit(t, pushDir, "log", "-1", "--format=%an <%ae>", branch); author != "newuser <new@example.com>" {
+		t.Errorf("書き換えたコミットがプッシュされていません: %s", author)
+	}
+}
+
+// TestProcessRepositoryStashAndPrune は自動stashした変更をクリーンアップの前に復元し、書き換え前の履歴が残らないことをテストする
+func TestProcessRepositoryStashAndPrune(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+	t.Setenv("GITHUB_COLLABORATORS", "")
+
+	repo := filepath.Join(t.TempDir(), "app")
+	runGit(t, filepath.Dir(repo), "init", repo)
+	if err := os.WriteFile(filepath.Join(repo, "file.txt"), []byte("initial\n"), 0644); err != nil {
+		t.Fatalf("ファイル作成エラー: %v", err)
+	}
+	runGit(t, repo, "add", "file.txt")
+	runGit(t, repo, "-c", "user.name=Old", "-c", "user.email=old@example.com", "commit", "-m", "initial")
+	runGit(t, repo, "remote", "add", "origin", "https://github.com/olduser/app.git")
+	oldHead := runGit(t, repo, "rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(repo, "file.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("ファイル変更エラー: %v", err)
+	}
+
+	pushDir := filepath.Join(t.TempDir(), "pushed.git")
+	runGit(t, filepath.Dir(pushDir), "init", "--bare", pushDir)
+
+	r := NewRewriter("", "newuser", "new@example.com")
+	r.SetProvider(&fakeProvider{pushDir: pushDir, repos: make(map[string]bool)})
+	r.SetDirtyPolicy(git.DirtyPolicyStash)
+	r.SetPruneOption(true, t.TempDir())
+
+	result := r.ProcessRepository(repo)
+	if !result.Success {
+		t.Fatalf("処理が成功することが期待されました: %v", result.Error)
+	}
+	if result.PruneError != nil || result.Prune == nil {
+		t.Fatalf("クリーンアップが実行されていません: %v", result.PruneError)
+	}
+	if !result.Preflight.Restored {
+		t.Error("自動stashした変更が復元されていません")
+	}
+	if result.Prune.StashKept {
+		t.Error("クリーンアップ時に自動stashが残っています")
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo, "file.txt")); string(content) != "changed\n" {
+		t.Errorf("作業ツリーの変更が復元されていません: %q", string(content))
+	}
+	if _, _, err := utils.RunCommand(repo, "git", "cat-file", "-e", oldHead); err == nil {
+		t.Errorf("書き換え前のコミット %s が削除されていません", oldHead)
+	}
+}
+
+// TestProcessRepositoryAcrossHosts はgithub.comのリモートを持つリポジトリを別のホストのサブグループに移行する処理をテストする
+func TestProcessRepositoryAcrossHosts(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+	t.Setenv("GITHUB_ORGANIZATION", "")
+	t.Setenv("GITHUB_COLLABORATORS", "")
+
+	repo := filepath.Join(t.TempDir(), "app")
+	runGit(t, filepath.Dir(repo), "init", repo)
+	runGit(t, repo, "-c", "user.name=Old", "-c", "user.email=old@example.com", "commit", "--allow-empty", "-m", "initial")
+	runGit(t, repo, "remote", "add", "origin", "git@github.com:olduser/app.git")
+
+	pushDir := filepath.Join(t.TempDir(), "pushed.git")
+	runGit(t, filepath.Dir(pushDir), "init", "--bare", pushDir)
+
+	destination := utils.NewHost("gitlab.example.com", true)
+	provider := &fakeProvider{pushDir: pushDir, host: &destination, ciState: "private", repos: make(map[string]bool)}
+	r := NewRewriter("", "newuser", "new@example.com")
+	r.SetProvider(provider)
+	r.SetRemoteHosts(git.RemoteHosts{Sources: []utils.Host{utils.DefaultHost}, Destination: destination})
+	r.SetOwnershipConfig("", "platform/backend")
+
+	result := r.ProcessRepository(repo)
+	if !result.Success {
+		t.Fatalf("処理が成功することが期待されました: %v", result.Error)
+	}
+
+	if url := runGit(t, repo, "remote", "get-url", "origin"); url != "https://gitlab.example.com/platform/backend/app" {
+		t.Errorf("remote URLが書き換え先のホストに変更されていません: %s", url)
+	}
+	// CIはGitLabのアクセスレベル（private）のまま元に戻す
+	if strings.Join(provider.ciStates, ",") != "disabled,private" {
+		t.Errorf("プッシュ後にCIが元の設定に戻されていません: %v", provider.ciStates)
+	}
+	if !provider.repos["platform/backend/app"] {
+		t.Errorf("書き換え先のサブグループにリポジトリが作成されていません: %v", provider.repos)
+	}
+	branch := runGit(t, repo, "branch", "--show-current")
+	if author := runGit(t, pushDir, "log", "-1", "--format=%an <%ae>", branch); author != "newuser <new@example.com>" {
+		t.Errorf("書き換えたコミットがプッシュされていません: %s", author)
+	}
+}
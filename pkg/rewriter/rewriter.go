@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"git-rewrite/pkg/git"
 	"git-rewrite/pkg/github"
@@ -17,11 +18,14 @@ type RewriteResult struct {
 	PushSucceeded    bool
 	Error            error
 	GitDir           string
+	Preflight        *git.PreflightReport // 作業ツリーの事前チェック結果
+	Prune            *git.PruneReport     // 書き換え後のクリーンアップ結果
+	PruneError       error                // クリーンアップ時のエラー
 }
 
 // Rewriter はGit履歴書き換えを行う
 type Rewriter struct {
-	GitHubClient           *github.Client
+	Provider               HostingProvider // 書き換え先のホスティングサービス（デフォルト: GitHub）
 	GitHubToken            string
 	GitHubUser             string
 	GitHubEmail            string
@@ -31,13 +35,24 @@ type Rewriter struct {
 	Organization           string
 	Private                bool
 	CollaboratorsString    string
-	DisableActions         bool // GitHub Actionsを無効化するかどうか
+	DisableActions         bool            // GitHub Actionsを無効化するかどうか
+	DirtyPolicy            string          // 作業ツリーが汚れている場合の処理方針
+	Prune                  bool            // 書き換え後に旧オブジェクトを削除するかどうか
+	BackupDir              string          // クリーンアップ前のバックアップ先
+	Remote                 string          // 確認・プッシュに使用するリモート
+	AllRemotes             bool            // 書き換え対象のホストを指す全リモートのURLを変更するかどうか
+	Hosts                  git.RemoteHosts // 書き換え対象のリモートのホストと書き換え先のホスト
+	RepoNamer              git.RepoNamer   // 書き換え先のリポジトリ名のルール（nilの場合は元の名前）
+	CreateMissingRemote    bool            // リモートがないリポジトリにディレクトリ名からリモートを追加するかどうか
+	OldRemoteName          string          // 移行前のURLを残すリモート名（{remote}は元のリモート名、空の場合は残さない）
+	RepoNameOverride       string          // バッチ内の書き換え先の衝突を避けるためのリポジトリ名（空の場合は変更しない）
+	HistoryOptions         git.RewriteOptions
 }
 
 // NewRewriter は新しいRewriterを作成する
 func NewRewriter(githubToken, githubUser, githubEmail string) *Rewriter {
 	return &Rewriter{
-		GitHubClient:           github.NewClient(githubToken),
+		Provider:               github.NewClient(githubToken),
 		GitHubToken:            githubToken,
 		GitHubUser:             githubUser,
 		GitHubEmail:            githubEmail,
@@ -45,13 +60,16 @@ func NewRewriter(githubToken, githubUser, githubEmail string) *Rewriter {
 		PushAll:                false,
 		Private:                true, // デフォルトはプライベート
 		DisableActions:         true, // デフォルトでActions制御を有効
+		DirtyPolicy:            git.DirtyPolicyRefuse,
+		Remote:                 git.DefaultRemote,
+		Hosts:                  git.DefaultRemoteHosts(),
 	}
 }
 
 // NewRewriterWithConfig はコラボレーター設定ファイル付きでRewriterを作成する
 func NewRewriterWithConfig(githubToken, githubUser, githubEmail, configPath string) *Rewriter {
 	return &Rewriter{
-		GitHubClient:           github.NewClient(githubToken),
+		Provider:               github.NewClient(githubToken),
 		GitHubToken:            githubToken,
 		GitHubUser:             githubUser,
 		GitHubEmail:            githubEmail,
@@ -59,9 +77,22 @@ func NewRewriterWithConfig(githubToken, githubUser, githubEmail, configPath stri
 		PushAll:                false,
 		Private:                true, // デフォルトはプライベート
 		DisableActions:         true, // デフォルトでActions制御を有効
+		DirtyPolicy:            git.DirtyPolicyRefuse,
+		Remote:                 git.DefaultRemote,
+		Hosts:                  git.DefaultRemoteHosts(),
 	}
 }
 
+// SetProvider は書き換え先のホスティングサービスを設定する
+func (r *Rewriter) SetProvider(provider HostingProvider) {
+	r.Provider = provider
+}
+
+// SetRemoteHosts は書き換え対象のリモートのホストと書き換え先のホストを設定する
+func (r *Rewriter) SetRemoteHosts(hosts git.RemoteHosts) {
+	r.Hosts = hosts
+}
+
 // SetPushAllOption はプッシュオプションを設定する
 func (r *Rewriter) SetPushAllOption(pushAll bool) {
 	r.PushAll = pushAll
@@ -88,14 +119,165 @@ func (r *Rewriter) SetDisableActionsOption(disableActions bool) {
 	r.DisableActions = disableActions
 }
 
+// SetDirtyPolicy は作業ツリーが汚れている場合の処理方針を設定する
+func (r *Rewriter) SetDirtyPolicy(policy string) {
+	r.DirtyPolicy = policy
+}
+
+// SetPruneOption は書き換え後のクリーンアップ設定を行う
+func (r *Rewriter) SetPruneOption(prune bool, backupDir string) {
+	r.Prune = prune
+	r.BackupDir = backupDir
+}
+
+// SetRemoteOption は使用するリモートと、全リモートのURLを変更するかどうかを設定する
+func (r *Rewriter) SetRemoteOption(remote string, allRemotes bool) {
+	if remote != "" {
+		r.Remote = remote
+	}
+	r.AllRemotes = allRemotes
+}
+
+// SetRepoNamer は書き換え先のリポジトリ名のルールを設定する
+func (r *Rewriter) SetRepoNamer(namer git.RepoNamer) {
+	r.RepoNamer = namer
+}
+
+// SetCreateMissingRemote はリモートがないリポジトリにリモートを追加するかどうかを設定する
+func (r *Rewriter) SetCreateMissingRemote(create bool) {
+	r.CreateMissingRemote = create
+}
+
+// SetOldRemoteName は移行前のURLを残すリモート名を設定する
+func (r *Rewriter) SetOldRemoteName(name string) {
+	r.OldRemoteName = name
+}
+
+// SetCommitCondition はauthor/emailを書き換えるコミットの条件を設定する
+func (r *Rewriter) SetCommitCondition(condition git.CommitCondition) {
+	r.HistoryOptions.Condition = condition
+}
+
+// WithIdentity は書き換え先のidentityと所有者を差し替えたRewriterのコピーを返す
+// 空の値は元の設定を引き継ぐ
+func (r *Rewriter) WithIdentity(user, email, owner, organization string) *Rewriter {
+	copied := *r
+	if user != "" {
+		copied.GitHubUser = user
+	}
+	if email != "" {
+		copied.GitHubEmail = email
+	}
+	if owner != "" || organization != "" {
+		copied.Owner = owner
+		copied.Organization = organization
+	}
+	return &copied
+}
+
+// WithRepoName はリポジトリ名を指定したRewriterのコピーを返す
+// バッチ内で書き換え先が衝突したリポジトリに使用する
+func (r *Rewriter) WithRepoName(repoName string) *Rewriter {
+	copied := *r
+	copied.RepoNameOverride = repoName
+	return &copied
+}
+
+// SetHistoryTruncation は履歴の畳み込み設定を行う
+func (r *Rewriter) SetHistoryTruncation(squash bool, truncateBefore time.Time) {
+	r.HistoryOptions.SquashHistory = squash
+	r.HistoryOptions.TruncateBefore = truncateBefore
+}
+
+// SetTreeNormalization は履歴中のツリーに適用する正規化を設定する
+func (r *Rewriter) SetTreeNormalization(normalization git.TreeNormalization) {
+	r.HistoryOptions.Normalization = normalization
+}
+
+// SetApplyGitignore は現在の.gitignoreを履歴全体に適用するかどうかを設定する
+func (r *Rewriter) SetApplyGitignore(apply bool) {
+	r.HistoryOptions.ApplyGitignore = apply
+}
+
+// SetRefRenamer は書き換え後に適用するブランチ・タグの名前変更ルールを設定する
+func (r *Rewriter) SetRefRenamer(renamer git.RefRenamer) {
+	r.HistoryOptions.RefRenamer = renamer
+}
+
+// SetLFSMigration は書き換え後にGit LFSへ移行するファイルの条件を設定する
+func (r *Rewriter) SetLFSMigration(migration *git.LFSMigration) {
+	r.HistoryOptions.LFSMigration = migration
+}
+
+// SetAnonymizer はidentityを仮名に置き換えるAnonymizerを設定する
+func (r *Rewriter) SetAnonymizer(anonymizer *git.Anonymizer) {
+	r.HistoryOptions.Anonymizer = anonymizer
+}
+
+// SetBatchProgress は全体の進捗表示に使用するBatchProgressを設定する
+func (r *Rewriter) SetBatchProgress(progress *git.BatchProgress) {
+	r.HistoryOptions.Progress = progress
+}
+
 // RewriteGitHistory はGit履歴を書き換える
 func (r *Rewriter) RewriteGitHistory(gitDir string) error {
-	return git.RewriteHistory(gitDir, r.GitHubUser, r.GitHubEmail)
+	return git.RewriteHistoryWithOptions(gitDir, r.GitHubUser, r.GitHubEmail, r.HistoryOptions)
 }
 
 // UpdateRemoteURL はリモートURLを更新する
+// AllRemotesが有効な場合は書き換え対象のホストを指す全リモートを更新する
+// OldRemoteNameが設定されている場合は変更前のURLをリモートとして残す
 func (r *Rewriter) UpdateRemoteURL(gitDir string) error {
-	return git.UpdateRemoteURL(gitDir, r.GitHubUser, r.Owner, r.Organization)
+	if r.OldRemoteName == "" {
+		return r.updateRemoteURL(gitDir)
+	}
+
+	before, err := git.RemoteURLs(gitDir)
+	if err != nil {
+		return err
+	}
+	if err := r.updateRemoteURL(gitDir); err != nil {
+		return err
+	}
+	after, err := git.RemoteURLs(gitDir)
+	if err != nil {
+		return err
+	}
+
+	for remote, oldURL := range before {
+		if newURL, ok := after[remote]; ok && newURL != oldURL {
+			if err := git.KeepOldRemote(gitDir, remote, git.OldRemoteName(r.OldRemoteName, remote), oldURL, newURL); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateRemoteURL は設定に応じてリモートの追加・URLの変更を行う
+func (r *Rewriter) updateRemoteURL(gitDir string) error {
+	if err := r.addOrUpdateRemoteURL(gitDir); err != nil || r.RepoNameOverride == "" {
+		return err
+	}
+	return git.SetRemoteRepoName(gitDir, r.Remote, r.RepoNameOverride)
+}
+
+// addOrUpdateRemoteURL はリモートがない場合は追加し、ある場合はURLを書き換え先のものに変更する
+func (r *Rewriter) addOrUpdateRemoteURL(gitDir string) error {
+	if r.CreateMissingRemote {
+		added, err := git.AddMissingRemote(gitDir, r.Remote, r.Hosts.Destination, r.GitHubUser, r.Owner, r.Organization, r.RepoNamer)
+		if err != nil {
+			return err
+		}
+		if added {
+			// 追加したリモートは既に書き換え先の所有者を指している
+			return nil
+		}
+	}
+	if r.AllRemotes {
+		return git.UpdateAllRemoteURLs(gitDir, r.Hosts, r.GitHubUser, r.Owner, r.Organization, r.RepoNamer)
+	}
+	return git.UpdateNamedRemoteURL(gitDir, r.Remote, r.Hosts, r.GitHubUser, r.Owner, r.Organization, r.RepoNamer)
 }
 
 // CreateInitialCommit は初期コミットを作成する
@@ -108,7 +290,7 @@ func (r *Rewriter) VerifyAndPushRemote(gitDir string) error {
 	fmt.Println("\n--- リモートリポジトリの確認とプッシュ ---")
 
 	// リモートURLを取得
-	stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", "origin")
+	stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", r.Remote)
 	if err != nil {
 		return fmt.Errorf("リモートURL取得エラー: %v", err)
 	}
@@ -117,21 +299,22 @@ func (r *Rewriter) VerifyAndPushRemote(gitDir string) error {
 	fmt.Printf("現在のリモートURL: %s\n", remoteURL)
 
 	// リモートURLからユーザー名とリポジトリ名を抽出
-	owner, repoName := utils.ExtractRepoInfoFromURL(remoteURL)
+	remote, err := r.Hosts.Destination.ParseRemoteURL(remoteURL)
 	if os.Getenv("GIT_REWRITE_DEBUG") != "" {
-		fmt.Printf("デバッグ: VerifyAndPushRemote URL解析結果 - URL: %s, Owner: '%s', Repo: '%s'\n", remoteURL, owner, repoName)
+		fmt.Printf("デバッグ: VerifyAndPushRemote URL解析結果 - URL: %s, 結果: %+v\n", remoteURL, remote)
 	}
-	if owner == "" || repoName == "" {
-		return fmt.Errorf("リモートURLからリポジトリ情報を抽出できませんでした: %s (解析結果: owner='%s', repo='%s')", remoteURL, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("リモートURLからリポジトリ情報を抽出できませんでした: %v", err)
 	}
+	owner, repoName := remote.Owner, remote.Repo
 
 	fmt.Printf("リポジトリ情報: %s/%s\n", owner, repoName)
 
 	// 期待されるオーナーを決定
 	expectedOwner := utils.GetTargetOwner(r.GitHubUser, r.Owner, r.Organization)
 
-	// リモートURLに期待されるオーナーが含まれているか確認
-	if !strings.Contains(remoteURL, expectedOwner) {
+	// リモートURLのオーナーが期待されるオーナーと一致するか確認（部分一致ではなく完全一致）
+	if !remote.HasOwner(expectedOwner) {
 		fmt.Printf("⚠️  警告: リモートリポジトリが %s に設定されていません。\n", expectedOwner)
 		fmt.Printf("   期待されるオーナー: %s\n", expectedOwner)
 		fmt.Printf("   実際のURL: %s\n", remoteURL)
@@ -140,9 +323,9 @@ func (r *Rewriter) VerifyAndPushRemote(gitDir string) error {
 
 	fmt.Printf("✅ リモートリポジトリが %s に設定されています。\n", expectedOwner)
 
-	// GitHubリポジトリの存在確認
-	fmt.Println("GitHubリポジトリの存在を確認しています...")
-	exists, err := r.GitHubClient.CheckRepoExists(owner, repoName)
+	// リポジトリの存在確認
+	fmt.Printf("%sリポジトリの存在を確認しています...\n", r.Provider.Name())
+	exists, err := r.Provider.RepoExists(owner, repoName)
 	if err != nil {
 		return fmt.Errorf("リポジトリ存在確認エラー: %v", err)
 	}
@@ -159,9 +342,10 @@ func (r *Rewriter) VerifyAndPushRemote(gitDir string) error {
 			defer os.Unsetenv("GITHUB_COLLABORATORS")
 		}
 
-		if err := r.GitHubClient.CreateRepoWithCollaborators(owner, repoName, r.Private, collaboratorConfig); err != nil {
+		if err := r.Provider.CreateRepo(owner, repoName, r.Private); err != nil {
 			return fmt.Errorf("リポジトリ作成エラー: %v", err)
 		}
+		grantAccess(r.Provider, owner, repoName, github.ResolveCollaborators(collaboratorConfig, repoName))
 	} else {
 		fmt.Printf("✅ リモートリポジトリ %s/%s が存在します。\n", owner, repoName)
 	}
@@ -179,8 +363,15 @@ func (r *Rewriter) VerifyAndPushRemote(gitDir string) error {
 		fmt.Println("✅ 既存のコミットが見つかりました。")
 	}
 
+	// LFSに移行したファイルの実体をコミットより先にアップロード
+	if r.HistoryOptions.LFSMigration != nil {
+		if err := git.PushLFSObjects(gitDir, r.Remote, r.Provider); err != nil {
+			return err
+		}
+	}
+
 	// リモートにプッシュ
-	if err := git.PushToRemote(gitDir, r.GitHubToken); err != nil {
+	if err := git.PushToNamedRemote(gitDir, r.Remote, r.Provider); err != nil {
 		return err
 	}
 
@@ -196,7 +387,7 @@ func (r *Rewriter) VerifyAndPushRemote(gitDir string) error {
 
 // PushAllBranchesAndTags はローカルの全ブランチとタグをリモートにプッシュする
 func (r *Rewriter) PushAllBranchesAndTags(gitDir string) error {
-	return git.PushAllBranchesAndTags(gitDir, r.GitHubToken)
+	return git.PushAllBranchesAndTagsToRemote(gitDir, r.Remote, r.Provider)
 }
 
 // ProcessRepository は単一のリポジトリを処理する
@@ -205,6 +396,16 @@ func (r *Rewriter) ProcessRepository(gitDir string) *RewriteResult {
 		GitDir: gitDir,
 	}
 
+	// 作業ツリーの事前チェック
+	preflight, err := git.RunPreflight(gitDir, r.DirtyPolicy)
+	result.Preflight = preflight
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	// 自動stashした変更は全処理の完了後に復元する（クリーンアップする場合はその前に復元済みのため何もしない）
+	defer git.RestorePreflight(gitDir, preflight)
+
 	// Git履歴の書き換え
 	if err := r.RewriteGitHistory(gitDir); err != nil {
 		result.Error = err
@@ -219,26 +420,26 @@ func (r *Rewriter) ProcessRepository(gitDir string) *RewriteResult {
 	}
 
 	// Actions制御が有効な場合、プッシュ前にActionsを無効化
-	var originalActionsState bool
+	var originalCIState string
 	var actionsControlled bool
 	if r.DisableActions {
 		// リモートURLからリポジトリ情報を取得
-		stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", "origin")
+		stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", r.Remote)
 		if err == nil {
 			remoteURL := strings.TrimSpace(stdout)
-			owner, repoName := utils.ExtractRepoInfoFromURL(remoteURL)
-			if owner != "" && repoName != "" {
-				// 現在のActions状態を保存
-				if currentState, err := r.GitHubClient.GetActionsEnabled(owner, repoName); err == nil {
-					originalActionsState = currentState
+			if remote, err := r.Hosts.Destination.ParseRemoteURL(remoteURL); err == nil {
+				owner, repoName := remote.Owner, remote.Repo
+				// 現在のCIの設定を保存
+				if currentState, err := r.Provider.CIState(owner, repoName); err == nil {
+					originalCIState = currentState
 					actionsControlled = true
 
 					// Actionsを無効化
-					if err := r.GitHubClient.SetActionsEnabled(owner, repoName, false); err != nil {
-						fmt.Printf("⚠️  GitHub Actions無効化に失敗しました: %v\n", err)
+					if err := r.Provider.DisableCI(owner, repoName); err != nil {
+						fmt.Printf("⚠️  %sのCI無効化に失敗しました: %v\n", r.Provider.Name(), err)
 					}
 				} else {
-					fmt.Printf("⚠️  GitHub Actions状態取得に失敗しました: %v\n", err)
+					fmt.Printf("⚠️  %sのCI状態取得に失敗しました: %v\n", r.Provider.Name(), err)
 				}
 			}
 		}
@@ -247,23 +448,15 @@ func (r *Rewriter) ProcessRepository(gitDir string) *RewriteResult {
 	// リモート確認とプッシュ
 	pushErr := r.VerifyAndPushRemote(gitDir)
 
-	// Actions制御が有効だった場合、プッシュ後にActionsを元の状態に戻す
+	// Actions制御が有効だった場合、プッシュ後にCIを戻す（戻し方はホスティングサービスごとに異なる）
 	if actionsControlled {
 		// リモートURLからリポジトリ情報を再取得（念のため）
-		stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", "origin")
+		stdout, _, err := utils.RunCommand(gitDir, "git", "remote", "get-url", r.Remote)
 		if err == nil {
 			remoteURL := strings.TrimSpace(stdout)
-			owner, repoName := utils.ExtractRepoInfoFromURL(remoteURL)
-			if owner != "" && repoName != "" {
-				// Actionsを元の状態に戻す（デフォルトは有効）
-				targetState := originalActionsState
-				if !originalActionsState {
-					// 元々無効だった場合でも、デフォルトで有効にする
-					targetState = true
-				}
-
-				if err := r.GitHubClient.SetActionsEnabled(owner, repoName, targetState); err != nil {
-					fmt.Printf("⚠️  GitHub Actions復元に失敗しました: %v\n", err)
+			if remote, err := r.Hosts.Destination.ParseRemoteURL(remoteURL); err == nil {
+				if err := r.Provider.RestoreCIState(remote.Owner, remote.Repo, originalCIState); err != nil {
+					fmt.Printf("⚠️  %sのCI復元に失敗しました: %v\n", r.Provider.Name(), err)
 				}
 			}
 		}
@@ -276,5 +469,22 @@ func (r *Rewriter) ProcessRepository(gitDir string) *RewriteResult {
 
 	result.Success = true
 	result.PushSucceeded = true
+
+	// 書き換え前のオブジェクトを削除（失敗しても処理結果には影響させない）
+	if r.Prune {
+		// 自動stashは書き換え前のコミットを親に持ち、旧履歴が削除されなくなるため先に復元する
+		if err := git.RestorePreflight(gitDir, preflight); err != nil {
+			result.PruneError = fmt.Errorf("自動stashを復元できなかったため、クリーンアップを行いませんでした（stashが書き換え前の履歴を参照しています）: %v", err)
+			fmt.Printf("⚠️  %v\n", result.PruneError)
+			return result
+		}
+		prune, err := git.PruneRepository(gitDir, r.BackupDir)
+		result.Prune = prune
+		if err != nil {
+			result.PruneError = err
+			fmt.Printf("⚠️  クリーンアップに失敗しました: %v\n", err)
+		}
+	}
+
 	return result
 }
@@ -181,6 +181,9 @@ func TestNewRewriterDefaults(t *testing.T) {
 			if !rewriter.DisableActions {
 				t.Error("デフォルトのDisableActionsはtrueであるべきです")
 			}
+			if rewriter.DirtyPolicy != "refuse" {
+				t.Errorf("デフォルトのDirtyPolicyはrefuseであるべきです。実際: %s", rewriter.DirtyPolicy)
+			}
 		})
 	}
 }
@@ -225,3 +228,26 @@ func TestRewriterTokenStorage(t *testing.T) {
 		})
 	}
 }
+
+// TestWithIdentity はWithIdentityメソッドをテストする
+func TestWithIdentity(t *testing.T) {
+	rewriter := NewRewriter("test-token", "testuser", "test@example.com")
+	rewriter.SetOwnershipConfig("", "defaultorg")
+
+	copied := rewriter.WithIdentity("workuser", "work@corp.example", "", "workorg")
+	if copied.GitHubUser != "workuser" || copied.GitHubEmail != "work@corp.example" || copied.Organization != "workorg" {
+		t.Errorf("identityが差し替えられていません: %+v", copied)
+	}
+	if rewriter.GitHubUser != "testuser" || rewriter.Organization != "defaultorg" {
+		t.Error("元のRewriterが変更されました")
+	}
+	if copied.GitHubToken != "test-token" {
+		t.Error("トークンが引き継がれていません")
+	}
+
+	// 空の値は元の設定を引き継ぐ
+	inherited := rewriter.WithIdentity("", "", "", "")
+	if inherited.GitHubUser != "testuser" || inherited.GitHubEmail != "test@example.com" || inherited.Organization != "defaultorg" {
+		t.Errorf("空の値で元の設定が引き継がれていません: %+v", inherited)
+	}
+}
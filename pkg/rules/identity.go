@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IdentityRule はパスのglobパターンに対応する書き換え先のidentityを表す
+// 空のフィールドはコマンドライン引数で指定された値を引き継ぐ
+type IdentityRule struct {
+	Path         string `json:"path"`         // 対象ディレクトリからの相対パス（globパターン）
+	User         string `json:"user"`         // 書き換え先のユーザー名
+	Email        string `json:"email"`        // 書き換え先のメールアドレス
+	Owner        string `json:"owner"`        // 個人リポジトリ所有者
+	Organization string `json:"organization"` // 組織名
+}
+
+// IdentityRules はディレクトリごとのidentity設定
+type IdentityRules struct {
+	Rules []IdentityRule `json:"rules"`
+}
+
+// LoadIdentityRules は設定ファイルからidentityルールを読み込む
+func LoadIdentityRules(path string) (*IdentityRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("identityルールファイル読み込みエラー: %v", err)
+	}
+
+	var rules IdentityRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("identityルールファイル解析エラー: %v", err)
+	}
+
+	for i, rule := range rules.Rules {
+		if strings.TrimSpace(rule.Path) == "" {
+			return nil, fmt.Errorf("identityルール %d 番目の path が空です", i+1)
+		}
+		if _, err := filepath.Match(rule.Path, ""); err != nil {
+			return nil, fmt.Errorf("identityルール %d 番目の path が不正です: %s", i+1, rule.Path)
+		}
+	}
+
+	return &rules, nil
+}
+
+// Match はリポジトリに一致する最初のルールを返す（一致しない場合はnil）
+func (r *IdentityRules) Match(rootDir, repoDir string) *IdentityRule {
+	if r == nil {
+		return nil
+	}
+	for i := range r.Rules {
+		if MatchPath(r.Rules[i].Path, rootDir, repoDir) {
+			return &r.Rules[i]
+		}
+	}
+	return nil
+}
+
+// MatchPath はリポジトリのパスがglobパターンに一致するかを判定する
+// パターンは対象ディレクトリからの相対パスまたは絶対パスで指定し、
+// リポジトリ自身または親ディレクトリのいずれかが一致すれば一致とみなす
+func MatchPath(pattern, rootDir, repoDir string) bool {
+	target := repoDir
+	if !filepath.IsAbs(pattern) {
+		rel, err := filepath.Rel(rootDir, repoDir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return false
+		}
+		target = rel
+	}
+
+	pattern = filepath.Clean(pattern)
+	for path := filepath.Clean(target); path != "." && path != string(filepath.Separator); path = filepath.Dir(path) {
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return true
+		}
+	}
+	return false
+}
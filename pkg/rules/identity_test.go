@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	root := "/home/user/src"
+
+	tests := []struct {
+		name     string
+		pattern  string
+		repoDir  string
+		expected bool
+	}{
+		{"直下のglob", "work/*", "/home/user/src/work/api", true},
+		{"ネストしたリポジトリ", "work/*", "/home/user/src/work/team/api", true},
+		{"ディレクトリ名のみ", "oss", "/home/user/src/oss/tool", true},
+		{"一致しない", "work/*", "/home/user/src/oss/tool", false},
+		{"対象ディレクトリ外", "work/*", "/home/user/other/work/api", false},
+		{"絶対パス", "/home/user/src/oss/*", "/home/user/src/oss/tool", true},
+		{"名前のglob", "*/legacy-*", "/home/user/src/work/legacy-api", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := MatchPath(tt.pattern, root, tt.repoDir); result != tt.expected {
+				t.Errorf("MatchPath(%q, %q): 期待値 %t, 実際 %t", tt.pattern, tt.repoDir, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLoadIdentityRules(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("正常なルールファイル", func(t *testing.T) {
+		path := filepath.Join(dir, "identities.json")
+		content := `{"rules":[{"path":"work/*","user":"workuser","email":"me@corp.example","organization":"corp"},{"path":"oss/*","user":"me","email":"me@personal.example"}]}`
+		os.WriteFile(path, []byte(content), 0644)
+
+		identityRules, err := LoadIdentityRules(path)
+		if err != nil {
+			t.Fatalf("LoadIdentityRulesでエラーが発生しました: %v", err)
+		}
+		if len(identityRules.Rules) != 2 {
+			t.Fatalf("期待されるルール数: 2, 実際: %d", len(identityRules.Rules))
+		}
+
+		rule := identityRules.Match("/src", "/src/work/api")
+		if rule == nil || rule.Email != "me@corp.example" || rule.Organization != "corp" {
+			t.Errorf("work/* のルールが一致しません: %+v", rule)
+		}
+		rule = identityRules.Match("/src", "/src/oss/tool")
+		if rule == nil || rule.Email != "me@personal.example" {
+			t.Errorf("oss/* のルールが一致しません: %+v", rule)
+		}
+		if rule := identityRules.Match("/src", "/src/misc/tool"); rule != nil {
+			t.Errorf("一致しないリポジトリでルールが返されました: %+v", rule)
+		}
+	})
+
+	t.Run("pathが空", func(t *testing.T) {
+		path := filepath.Join(dir, "empty-path.json")
+		os.WriteFile(path, []byte(`{"rules":[{"path":"","user":"u"}]}`), 0644)
+		if _, err := LoadIdentityRules(path); err == nil {
+			t.Error("pathが空のルールでエラーが期待されました")
+		}
+	})
+
+	t.Run("不正なJSON", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		os.WriteFile(path, []byte(`{"rules":`), 0644)
+		if _, err := LoadIdentityRules(path); err == nil {
+			t.Error("不正なJSONでエラーが期待されました")
+		}
+	})
+
+	t.Run("存在しないファイル", func(t *testing.T) {
+		if _, err := LoadIdentityRules(filepath.Join(dir, "missing.json")); err == nil {
+			t.Error("存在しないファイルでエラーが期待されました")
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"git-rewrite/pkg/utils"
+)
+
+// テンプレートで使用できるプレースホルダー
+const (
+	namePlaceholderOrg  = "{org}"  // 元のオーナー（ユーザーまたは組織）
+	namePlaceholderRepo = "{repo}" // 元のリポジトリ名
+)
+
+// namePlaceholderPattern はテンプレート中のプレースホルダーに一致する
+var namePlaceholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// RepoNameRules は書き換え先のリポジトリ名を決定するルール
+// 対応表に一致する場合は対応表の名前を、それ以外はテンプレートから生成した名前を使用する
+type RepoNameRules struct {
+	Template string            // リポジトリ名のテンプレート（例: {org}-{repo}, legacy-{repo}）
+	Names    map[string]string // 小文字の "owner/repo" または "repo" → 新しいリポジトリ名
+}
+
+// NewRepoNameRules はテンプレートと対応表ファイルから名前のルールを作成する
+// どちらも空の場合はnilを返す
+func NewRepoNameRules(template, mapPath string) (*RepoNameRules, error) {
+	if template == "" && mapPath == "" {
+		return nil, nil
+	}
+
+	rules := &RepoNameRules{Template: template, Names: make(map[string]string)}
+	if template != "" {
+		if err := validateNameTemplate(template); err != nil {
+			return nil, err
+		}
+	}
+	if mapPath != "" {
+		names, err := loadRepoNameMap(mapPath)
+		if err != nil {
+			return nil, err
+		}
+		rules.Names = names
+	}
+	return rules, nil
+}
+
+// loadRepoNameMap はリポジトリ名の対応表ファイルを読み込む
+// キーは "owner/repo" または "repo"（大文字・小文字は区別しない）
+func loadRepoNameMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("リポジトリ名対応表ファイル読み込みエラー: %v", err)
+	}
+
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("リポジトリ名対応表ファイル解析エラー: %v", err)
+	}
+
+	names := make(map[string]string, len(entries))
+	for oldName, newName := range entries {
+		key := strings.ToLower(strings.TrimSpace(oldName))
+		if key == "" || strings.Count(key, "/") > 1 {
+			return nil, fmt.Errorf("リポジトリ名対応表のキーが不正です（repo または owner/repo を指定してください）: %s", oldName)
+		}
+		if !utils.IsValidRepoName(newName) {
+			return nil, fmt.Errorf("リポジトリ名対応表の %s の変更後の名前が不正です: %s", oldName, newName)
+		}
+		if _, exists := names[key]; exists {
+			return nil, fmt.Errorf("リポジトリ名対応表のキーが重複しています: %s", oldName)
+		}
+		names[key] = newName
+	}
+	return names, nil
+}
+
+// validateNameTemplate はテンプレートのプレースホルダーを検証する
+func validateNameTemplate(template string) error {
+	if !strings.Contains(template, namePlaceholderRepo) {
+		// {repo}を含まないと全リポジトリが同じ名前になる
+		return fmt.Errorf("--name-template には %s を含めてください: %s", namePlaceholderRepo, template)
+	}
+	for _, placeholder := range namePlaceholderPattern.FindAllString(template, -1) {
+		if placeholder != namePlaceholderOrg && placeholder != namePlaceholderRepo {
+			return fmt.Errorf("--name-template のプレースホルダー %s は使用できません（%s, %s のみ使用できます）", placeholder, namePlaceholderOrg, namePlaceholderRepo)
+		}
+	}
+	return nil
+}
+
+// RepoName は書き換え先のリポジトリ名を返す（git.RepoNamerの実装）
+func (r *RepoNameRules) RepoName(owner, repo string) (string, error) {
+	if r == nil {
+		return repo, nil
+	}
+
+	if name, ok := r.Names[strings.ToLower(owner+"/"+repo)]; ok {
+		return name, nil
+	}
+	if name, ok := r.Names[strings.ToLower(repo)]; ok {
+		return name, nil
+	}
+	if r.Template == "" {
+		return repo, nil
+	}
+
+	name := strings.NewReplacer(namePlaceholderOrg, owner, namePlaceholderRepo, repo).Replace(r.Template)
+	if !utils.IsValidRepoName(name) {
+		return "", fmt.Errorf("テンプレート %s から生成したリポジトリ名が不正です: %s", r.Template, name)
+	}
+	return name, nil
+}
@@ -0,0 +1,88 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoNameRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.json")
+	content := `{"olduser/tools": "shared-tools", "Website": "corporate-site", "tools": "common-tools"}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("ファイル作成エラー: %v", err)
+	}
+
+	rules, err := NewRepoNameRules("{org}-{repo}", path)
+	if err != nil {
+		t.Fatalf("NewRepoNameRulesでエラーが発生しました: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		owner    string
+		repo     string
+		expected string
+	}{
+		{"owner/repoの対応表", "olduser", "tools", "shared-tools"},
+		{"repoの対応表", "another", "tools", "common-tools"},
+		{"大文字・小文字を区別しない", "olduser", "website", "corporate-site"},
+		{"テンプレート", "olduser", "app", "olduser-app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := rules.RepoName(tt.owner, tt.repo)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if name != tt.expected {
+				t.Errorf("期待値 %s, 実際 %s", tt.expected, name)
+			}
+		})
+	}
+
+	var empty *RepoNameRules
+	if name, err := empty.RepoName("olduser", "app"); err != nil || name != "app" {
+		t.Errorf("nilのルールは元の名前を返すことが期待されました: %s, %v", name, err)
+	}
+}
+
+func TestNewRepoNameRulesInvalid(t *testing.T) {
+	if rules, err := NewRepoNameRules("", ""); err != nil || rules != nil {
+		t.Errorf("未指定の場合はnilが期待されました: %+v, %v", rules, err)
+	}
+
+	for _, template := range []string{"legacy", "{owner}-{repo}", "{repo"} {
+		if _, err := NewRepoNameRules(template, ""); err == nil {
+			t.Errorf("%s: エラーが期待されました", template)
+		}
+	}
+
+	// 生成した名前が不正な場合はエラーにする
+	rules, err := NewRepoNameRules("{repo} old", "")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if _, err := rules.RepoName("olduser", "app"); err == nil {
+		t.Error("空白を含むリポジトリ名でエラーが期待されました")
+	}
+
+	dir := t.TempDir()
+	invalid := map[string]string{
+		"不正な名前":   `{"tools": "shared tools"}`,
+		"不正なキー":   `{"a/b/c": "tools"}`,
+		"重複するキー":  `{"Tools": "a", "tools": "b"}`,
+		"不正なJSON": `{"tools": `,
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, "invalid.json")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("ファイル作成エラー: %v", err)
+			}
+			if _, err := NewRepoNameRules("", path); err == nil {
+				t.Error("エラーが期待されましたが、エラーが発生しませんでした")
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// OwnerRule は条件に一致するリポジトリの書き換え先の所有者を表す
+// 指定した条件（source, path, repo）がすべて一致した場合に適用する
+type OwnerRule struct {
+	Source       string `json:"source"`       // 元のリモートの owner/repo（globパターン、owner のみの場合は owner/*）
+	Path         string `json:"path"`         // 対象ディレクトリからの相対パス（globパターン）
+	Repo         string `json:"repo"`         // 元のリポジトリ名（globパターン）
+	Owner        string `json:"owner"`        // 書き換え先の個人リポジトリ所有者
+	Organization string `json:"organization"` // 書き換え先の組織名
+}
+
+// OwnerRules はリポジトリを書き換え先の所有者に振り分けるルール
+type OwnerRules struct {
+	Rules []OwnerRule `json:"rules"`
+}
+
+// LoadOwnerRules は設定ファイルから所有者の振り分けルールを読み込む
+func LoadOwnerRules(rulesPath string) (*OwnerRules, error) {
+	data, err := os.ReadFile(rulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("所有者ルールファイル読み込みエラー: %v", err)
+	}
+
+	var rules OwnerRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("所有者ルールファイル解析エラー: %v", err)
+	}
+
+	for i := range rules.Rules {
+		rule := &rules.Rules[i]
+		if rule.Source == "" && rule.Path == "" && rule.Repo == "" {
+			return nil, fmt.Errorf("所有者ルール %d 番目に source, path, repo のいずれも指定されていません", i+1)
+		}
+		if (rule.Owner == "") == (rule.Organization == "") {
+			return nil, fmt.Errorf("所有者ルール %d 番目には owner または organization のどちらか一方を指定してください", i+1)
+		}
+		if rule.Source != "" && !strings.Contains(rule.Source, "/") {
+			rule.Source += "/*"
+		}
+		for _, pattern := range []string{rule.Source, rule.Repo} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("所有者ルール %d 番目のパターンが不正です: %s", i+1, pattern)
+			}
+		}
+		if _, err := filepath.Match(rule.Path, ""); err != nil {
+			return nil, fmt.Errorf("所有者ルール %d 番目の path が不正です: %s", i+1, rule.Path)
+		}
+	}
+
+	return &rules, nil
+}
+
+// Match はリポジトリに一致する最初のルールを返す（一致しない場合はnil）
+// sourceOwner, sourceRepo は元のリモートのオーナーとリポジトリ名（リモートがない場合はオーナーが空）
+// 再実行時のため、どの条件にも一致せず、元のオーナーが既にいずれかのルールの書き換え先である場合はそのルールを返す
+func (r *OwnerRules) Match(rootDir, repoDir, sourceOwner, sourceRepo string) *OwnerRule {
+	if r == nil {
+		return nil
+	}
+	for i := range r.Rules {
+		if r.Rules[i].matches(rootDir, repoDir, sourceOwner, sourceRepo) {
+			return &r.Rules[i]
+		}
+	}
+	for i := range r.Rules {
+		if sourceOwner != "" && strings.EqualFold(sourceOwner, r.Rules[i].Target()) {
+			return &r.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Target は書き換え先の所有者を返す
+func (r *OwnerRule) Target() string {
+	if r.Owner != "" {
+		return r.Owner
+	}
+	return r.Organization
+}
+
+// matches はリポジトリがルールのすべての条件に一致するかを判定する
+// GitHubのオーナー名・リポジトリ名は大文字・小文字を区別しない
+func (r *OwnerRule) matches(rootDir, repoDir, sourceOwner, sourceRepo string) bool {
+	if r.Source != "" {
+		if sourceOwner == "" {
+			return false
+		}
+		source := strings.ToLower(sourceOwner + "/" + sourceRepo)
+		if matched, _ := path.Match(strings.ToLower(r.Source), source); !matched {
+			return false
+		}
+	}
+	if r.Path != "" && !MatchPath(r.Path, rootDir, repoDir) {
+		return false
+	}
+	if r.Repo != "" {
+		if matched, _ := path.Match(strings.ToLower(r.Repo), strings.ToLower(sourceRepo)); !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOwnerRules(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("正常なルールファイル", func(t *testing.T) {
+		path := filepath.Join(dir, "owners.json")
+		content := `{"rules":[
+			{"source":"oldcorp-frontend/*","organization":"newcorp-web"},
+			{"source":"oldcorp","repo":"*-infra","organization":"newcorp-ops"},
+			{"path":"personal/*","owner":"me"}
+		]}`
+		os.WriteFile(path, []byte(content), 0644)
+
+		ownerRules, err := LoadOwnerRules(path)
+		if err != nil {
+			t.Fatalf("LoadOwnerRulesでエラーが発生しました: %v", err)
+		}
+
+		tests := []struct {
+			name        string
+			repoDir     string
+			sourceOwner string
+			sourceRepo  string
+			expected    string
+		}{
+			{"元のオーナー", "/src/web/app", "OldCorp-Frontend", "app", "newcorp-web"},
+			{"オーナーとリポジトリ名", "/src/ops/k8s-infra", "oldcorp", "k8s-infra", "newcorp-ops"},
+			{"リポジトリ名が一致しない", "/src/ops/api", "oldcorp", "api", ""},
+			{"パス", "/src/personal/notes", "", "notes", "me"},
+			{"再実行時は書き換え先のオーナーのまま", "/src/web/app", "newcorp-web", "app", "newcorp-web"},
+			{"一致しない", "/src/misc/tool", "someone", "tool", ""},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rule := ownerRules.Match("/src", tt.repoDir, tt.sourceOwner, tt.sourceRepo)
+				actual := ""
+				if rule != nil {
+					actual = rule.Target()
+				}
+				if actual != tt.expected {
+					t.Errorf("期待値 %q, 実際 %q", tt.expected, actual)
+				}
+			})
+		}
+
+		var nilRules *OwnerRules
+		if rule := nilRules.Match("/src", "/src/web/app", "oldcorp-frontend", "app"); rule != nil {
+			t.Errorf("nilのルールで一致しました: %+v", rule)
+		}
+	})
+
+	t.Run("不正なルール", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"条件なし":                  `{"rules":[{"organization":"org"}]}`,
+			"書き換え先なし":               `{"rules":[{"source":"old"}]}`,
+			"ownerとorganizationの両方": `{"rules":[{"source":"old","owner":"me","organization":"org"}]}`,
+			"不正なパターン":               `{"rules":[{"repo":"[","organization":"org"}]}`,
+			"不正なJSON":               `{"rules":`,
+		} {
+			path := filepath.Join(dir, "invalid.json")
+			os.WriteFile(path, []byte(content), 0644)
+			if _, err := LoadOwnerRules(path); err == nil {
+				t.Errorf("%s: エラーが期待されました", name)
+			}
+		}
+	})
+
+	t.Run("存在しないファイル", func(t *testing.T) {
+		if _, err := LoadOwnerRules(filepath.Join(dir, "missing.json")); err == nil {
+			t.Error("存在しないファイルでエラーが期待されました")
+		}
+	})
+}
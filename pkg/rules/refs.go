@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"git-rewrite/pkg/git"
+)
+
+// RefRule はブランチ・タグ名のglobパターンに対する変更内容を表す
+// Rename・Prefix・Dropのいずれか1つを指定する
+type RefRule struct {
+	Match  string `json:"match"`  // 対象の名前（globパターン。例: master, tmp/*）
+	Rename string `json:"rename"` // 変更後の名前（Matchの * に一致した部分を * で参照できる）
+	Prefix string `json:"prefix"` // 名前に付与する接頭辞（既に付与されている場合は変更しない）
+	Drop   bool   `json:"drop"`   // trueの場合は参照を削除する
+}
+
+// RefRules はブランチ・タグの名前変更ルール
+type RefRules struct {
+	Branches []RefRule `json:"branches"`
+	Tags     []RefRule `json:"tags"`
+}
+
+// LoadRefRules は設定ファイルから名前変更ルールを読み込む
+func LoadRefRules(path string) (*RefRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("参照名ルールファイル読み込みエラー: %v", err)
+	}
+
+	var rules RefRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("参照名ルールファイル解析エラー: %v", err)
+	}
+
+	for i, rule := range rules.Branches {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("参照名ルール branches の %d 番目: %v", i+1, err)
+		}
+	}
+	for i, rule := range rules.Tags {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("参照名ルール tags の %d 番目: %v", i+1, err)
+		}
+	}
+
+	return &rules, nil
+}
+
+// validate はルールの内容を検証する
+func (r *RefRule) validate() error {
+	if strings.TrimSpace(r.Match) == "" {
+		return fmt.Errorf("match が空です")
+	}
+	if _, err := path.Match(r.Match, ""); err != nil {
+		return fmt.Errorf("match が不正です: %s", r.Match)
+	}
+
+	actions := 0
+	for _, set := range []bool{r.Rename != "", r.Prefix != "", r.Drop} {
+		if set {
+			actions++
+		}
+	}
+	if actions != 1 {
+		return fmt.Errorf("rename, prefix, drop のいずれか1つを指定してください")
+	}
+	if strings.Contains(r.Rename, "*") && strings.Count(r.Match, "*") != 1 {
+		return fmt.Errorf("rename で * を使用する場合、match には * を1つだけ指定してください")
+	}
+	return nil
+}
+
+// RenameRef は参照の変更後の名前を返す（git.RefRenamerの実装）
+// 削除する場合はfalseを返す。一致するルールがない場合は元の名前を返す
+func (r *RefRules) RenameRef(refType, name string) (string, bool) {
+	if r == nil {
+		return name, true
+	}
+
+	list := r.Branches
+	if refType == git.RefTypeTag {
+		list = r.Tags
+	}
+	for _, rule := range list {
+		if matched, _ := path.Match(rule.Match, name); !matched {
+			continue
+		}
+		switch {
+		case rule.Drop:
+			return "", false
+		case rule.Prefix != "":
+			if strings.HasPrefix(name, rule.Prefix) {
+				return name, true
+			}
+			return rule.Prefix + name, true
+		default:
+			return expandWildcard(rule.Match, rule.Rename, name), true
+		}
+	}
+	return name, true
+}
+
+// expandWildcard はreplacementの * をpatternの * に一致した部分に置き換える
+func expandWildcard(pattern, replacement, name string) string {
+	if !strings.Contains(replacement, "*") {
+		return replacement
+	}
+	index := strings.Index(pattern, "*")
+	prefix, suffix := pattern[:index], pattern[index+1:]
+	captured := strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+	return strings.Replace(replacement, "*", captured, 1)
+}
@@ -0,0 +1,98 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git-rewrite/pkg/git"
+)
+
+func TestRefRulesRenameRef(t *testing.T) {
+	rules := &RefRules{
+		Branches: []RefRule{
+			{Match: "master", Rename: "main"},
+			{Match: "tmp/*", Drop: true},
+			{Match: "release/*", Rename: "releases/*"},
+		},
+		Tags: []RefRule{
+			{Match: "*", Prefix: "legacy-"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		refType      string
+		ref          string
+		expectedName string
+		expectedKeep bool
+	}{
+		{"名前の変更", git.RefTypeBranch, "master", "main", true},
+		{"ブランチの削除", git.RefTypeBranch, "tmp/experiment", "", false},
+		{"ワイルドカードの置換", git.RefTypeBranch, "release/1.0", "releases/1.0", true},
+		{"一致しないブランチ", git.RefTypeBranch, "develop", "develop", true},
+		{"タグの接頭辞", git.RefTypeTag, "v1.0", "legacy-v1.0", true},
+		{"接頭辞は重複させない", git.RefTypeTag, "legacy-v1.0", "legacy-v1.0", true},
+		{"ブランチのルールはタグに適用しない", git.RefTypeTag, "master", "legacy-master", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, keep := rules.RenameRef(tt.refType, tt.ref)
+			if name != tt.expectedName || keep != tt.expectedKeep {
+				t.Errorf("RenameRef(%q, %q): 期待値 (%q, %t), 実際 (%q, %t)", tt.refType, tt.ref, tt.expectedName, tt.expectedKeep, name, keep)
+			}
+		})
+	}
+
+	// nilのルールは名前を変更しない
+	var empty *RefRules
+	if name, keep := empty.RenameRef(git.RefTypeBranch, "master"); name != "master" || !keep {
+		t.Errorf("nilのルールで名前が変更されました: %q, %t", name, keep)
+	}
+}
+
+func TestLoadRefRules(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("正常なルールファイル", func(t *testing.T) {
+		path := filepath.Join(dir, "refs.json")
+		content := `{"branches": [{"match": "master", "rename": "main"}, {"match": "tmp/*", "drop": true}], "tags": [{"match": "*", "prefix": "legacy-"}]}`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("ファイル作成エラー: %v", err)
+		}
+
+		rules, err := LoadRefRules(path)
+		if err != nil {
+			t.Fatalf("LoadRefRulesでエラーが発生しました: %v", err)
+		}
+		if len(rules.Branches) != 2 || len(rules.Tags) != 1 {
+			t.Errorf("ルール数が正しくありません: %+v", rules)
+		}
+	})
+
+	invalid := map[string]string{
+		"matchが空":    `{"branches": [{"rename": "main"}]}`,
+		"操作が複数":      `{"branches": [{"match": "master", "rename": "main", "drop": true}]}`,
+		"操作がない":      `{"tags": [{"match": "v*"}]}`,
+		"ワイルドカードが不足": `{"branches": [{"match": "master", "rename": "main-*"}]}`,
+		"不正なJSON":    `{"branches": [`,
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, "invalid.json")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("ファイル作成エラー: %v", err)
+			}
+			if _, err := LoadRefRules(path); err == nil {
+				t.Error("エラーが期待されました")
+			}
+		})
+	}
+
+	t.Run("ファイルが存在しない", func(t *testing.T) {
+		if _, err := LoadRefRules(filepath.Join(dir, "missing.json")); err == nil {
+			t.Error("エラーが期待されました")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// GitHubのホスト
+const (
+	DefaultGitHubHost   = "github.com"
+	defaultGitHubAPIURL = "https://api.github.com"
+)
+
+// Host はリポジトリのホスティングサービスのホスト（github.com、GitHub Enterprise Server、GitLabなど）
+type Host struct {
+	Name   string // ホスト名（ポートを指定する場合は host:port）
+	Nested bool   // オーナーに階層（GitLabのサブグループ、例: group/subgroup）を許可するかどうか
+}
+
+// DefaultHost はgithub.comを表すHost
+var DefaultHost = Host{Name: DefaultGitHubHost}
+
+// NewHost はホスト名を正規化してHostを作成する（例: https://ghe.corp/ → ghe.corp）
+// ホスト名が空の場合はgithub.comとする
+func NewHost(name string, nested bool) Host {
+	name = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(name), "https://"), "http://")
+	name = strings.TrimSuffix(name, "/")
+	if name == "" {
+		name = DefaultGitHubHost
+	}
+	return Host{Name: name, Nested: nested}
+}
+
+// Hostname はポートを除いたホスト名を返す
+func (h Host) Hostname() string {
+	if hostname, _, err := net.SplitHostPort(h.Name); err == nil {
+		return hostname
+	}
+	return h.Name
+}
+
+// Port はホストに指定されたポートを返す（指定されていない場合は空）
+func (h Host) Port() string {
+	if _, port, err := net.SplitHostPort(h.Name); err == nil {
+		return port
+	}
+	return ""
+}
+
+// IsGitHubDotCom はgithub.comかどうかを返す
+func (h Host) IsGitHubDotCom() bool {
+	return strings.EqualFold(h.Hostname(), DefaultGitHubHost)
+}
+
+// GitHubAPIURL はホストのGitHub REST APIのベースURLを返す
+func (h Host) GitHubAPIURL() string {
+	if h.IsGitHubDotCom() {
+		return defaultGitHubAPIURL
+	}
+	// GitHub Enterprise ServerのREST APIは /api/v3 以下で提供される
+	return "https://" + h.Name + "/api/v3"
+}
+
+// Matches はリモートURLがこのホストのリポジトリを指しているかどうかを返す
+func (h Host) Matches(remote RemoteURL) bool {
+	if !strings.EqualFold(remote.Host, h.Hostname()) {
+		return false
+	}
+	return h.Nested || !strings.Contains(remote.Owner, "/")
+}
+
+// ParseRemoteURL はこのホストのリモートURLを解析する
+func (h Host) ParseRemoteURL(raw string) (RemoteURL, error) {
+	remote, err := ParseRemoteURL(raw)
+	if err != nil {
+		return RemoteURL{}, err
+	}
+	if !h.Matches(remote) {
+		if strings.EqualFold(remote.Host, h.Hostname()) {
+			return RemoteURL{}, fmt.Errorf("リモートURLからオーナーとリポジトリ名を取得できません: %s", raw)
+		}
+		return RemoteURL{}, fmt.Errorf("%s のリポジトリではありません: %s", h.Name, raw)
+	}
+	return remote, nil
+}
+
+// RemoteURL はこのホストのリポジトリを指すHTTPS形式のリモートURLを返す
+func (h Host) RemoteURL(owner, repo string) RemoteURL {
+	return RemoteURL{Scheme: "https", Host: h.Hostname(), Port: h.Port(), Owner: owner, Repo: repo}
+}
+
+// TokenURL はリモートURLをこのホストの認証情報付きHTTPS URL（例: https://token@host/owner/repo.git）に変換する
+func (h Host) TokenURL(remoteURL, user, password string) (string, error) {
+	remote, err := h.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return "", fmt.Errorf("無効なリモートURL: %s", remoteURL)
+	}
+	tokenURL := h.RemoteURL(remote.Owner, remote.Repo)
+	tokenURL.User = user
+	tokenURL.Password = password
+	tokenURL.GitSuffix = true
+	return tokenURL.String(), nil
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// リモートURLの形式
+const (
+	RemoteSchemeSCP = "scp" // git@github.com:owner/repo.git 形式
+)
+
+// remoteURLSchemes はURL形式で受け付けるスキーム
+var remoteURLSchemes = map[string]bool{
+	"https":   true,
+	"http":    true,
+	"ssh":     true,
+	"git+ssh": true,
+	"git":     true,
+}
+
+var (
+	// remoteNamePattern はGitHubのオーナー名・リポジトリ名として使用できる文字列に一致する
+	remoteNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	// remoteHostPattern はホスト名に一致する
+	remoteHostPattern = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+	// scpURLPattern は [user@]host:path 形式に一致する
+	scpURLPattern = regexp.MustCompile(`^(?:([^@/:\s]+)@)?([A-Za-z0-9.-]+):(.*)$`)
+)
+
+// RemoteURL はGitのリモートURLを構造化したもの
+type RemoteURL struct {
+	Scheme    string // https, http, ssh, git+ssh, git, またはscp形式の場合はRemoteSchemeSCP
+	User      string // ユーザー名（例: git@ の git、HTTPSに埋め込まれた認証情報のユーザー名）
+	Password  string // HTTPSに埋め込まれたパスワード・トークン
+	Host      string // ホスト名（ポートを含まない）
+	Port      string // ポート番号（指定されていない場合は空）
+	Owner     string // オーナー（ユーザーまたは組織。GitLabのサブグループの場合は group/subgroup）
+	Repo      string // リポジトリ名（.gitを含まない）
+	GitSuffix bool   // パスが.gitで終わっているかどうか
+}
+
+// ParseRemoteURL はリモートURLを解析する（ホストは問わない）
+// パスが3階層以上の場合は最後をリポジトリ名、それ以外をオーナーとする（ホストごとの可否はHost.Matchesで判定する）
+// 対応する形式: https://, http://, ssh://, git+ssh://, git:// とscp形式（user@host:owner/repo）
+func ParseRemoteURL(raw string) (RemoteURL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return RemoteURL{}, fmt.Errorf("リモートURLが空です")
+	}
+
+	var remote RemoteURL
+	var path string
+	if strings.Contains(raw, "://") {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return RemoteURL{}, fmt.Errorf("リモートURLの解析に失敗しました: %s: %v", raw, err)
+		}
+		if !remoteURLSchemes[parsed.Scheme] {
+			return RemoteURL{}, fmt.Errorf("サポートされていないスキームです: %s", raw)
+		}
+		if parsed.Opaque != "" || parsed.RawQuery != "" || parsed.Fragment != "" || parsed.ForceQuery {
+			return RemoteURL{}, fmt.Errorf("リモートURLにクエリ・フラグメントは指定できません: %s", raw)
+		}
+		remote.Scheme = parsed.Scheme
+		if parsed.User != nil {
+			remote.User = parsed.User.Username()
+			remote.Password, _ = parsed.User.Password()
+		}
+		remote.Host = parsed.Hostname()
+		remote.Port = parsed.Port()
+		path = parsed.Path
+	} else {
+		matches := scpURLPattern.FindStringSubmatch(raw)
+		if matches == nil {
+			return RemoteURL{}, fmt.Errorf("リモートURLが想定外の形式です: %s", raw)
+		}
+		remote.Scheme = RemoteSchemeSCP
+		remote.User = matches[1]
+		remote.Host = matches[2]
+		path = matches[3]
+	}
+
+	if !remoteHostPattern.MatchString(remote.Host) {
+		return RemoteURL{}, fmt.Errorf("リモートURLのホストが不正です: %s", raw)
+	}
+
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/")
+	if strings.HasSuffix(path, ".git") {
+		remote.GitSuffix = true
+		path = strings.TrimSuffix(path, ".git")
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return RemoteURL{}, fmt.Errorf("リモートURLからオーナーとリポジトリ名を取得できません: %s", raw)
+	}
+	for _, part := range parts {
+		if !remoteNamePattern.MatchString(part) {
+			return RemoteURL{}, fmt.Errorf("リモートURLからオーナーとリポジトリ名を取得できません: %s", raw)
+		}
+	}
+	remote.Owner = strings.Join(parts[:len(parts)-1], "/")
+	remote.Repo = parts[len(parts)-1]
+	return remote, nil
+}
+
+// String はリモートURLを文字列に変換する
+func (r RemoteURL) String() string {
+	path := r.Owner + "/" + r.Repo
+	if r.GitSuffix {
+		path += ".git"
+	}
+
+	if r.Scheme == RemoteSchemeSCP {
+		if r.User != "" {
+			return fmt.Sprintf("%s@%s:%s", r.User, r.Host, path)
+		}
+		return fmt.Sprintf("%s:%s", r.Host, path)
+	}
+
+	formatted := url.URL{Scheme: r.Scheme, Host: r.Host, Path: "/" + path}
+	if r.Port != "" {
+		formatted.Host = net.JoinHostPort(r.Host, r.Port)
+	}
+	if r.Password != "" {
+		formatted.User = url.UserPassword(r.User, r.Password)
+	} else if r.User != "" {
+		formatted.User = url.User(r.User)
+	}
+	return formatted.String()
+}
+
+// IsHTTP はHTTP(S)形式のURLかどうかを返す
+func (r RemoteURL) IsHTTP() bool {
+	return r.Scheme == "https" || r.Scheme == "http"
+}
+
+// HasOwner はオーナーが一致するかどうかを返す（GitHubのオーナー名は大文字・小文字を区別しない）
+func (r RemoteURL) HasOwner(owner string) bool {
+	return strings.EqualFold(r.Owner, owner)
+}
+
+// IsValidRepoName はGitHubのオーナー名・リポジトリ名として使用できるかどうかを返す
+func IsValidRepoName(name string) bool {
+	return remoteNamePattern.MatchString(name)
+}
@@ -5,7 +5,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"unicode/utf8"
 )
@@ -20,42 +19,26 @@ func SafeDecode(data []byte) string {
 }
 
 // ExtractRepoInfoFromURL はリモートURLからユーザー名とリポジトリ名を抽出する
+// 対象はgithub.comのURLのみ（解析できない場合は空文字列を返す）
 func ExtractRepoInfoFromURL(remoteURL string) (string, string) {
-	// URLをトリムして余分な空白文字を除去
-	remoteURL = strings.TrimSpace(remoteURL)
-
 	// デバッグモードの場合のみ詳細情報を表示
 	debug := os.Getenv("GIT_REWRITE_DEBUG") != ""
 	if debug {
 		fmt.Printf("デバッグ: ExtractRepoInfoFromURL 入力URL: '%s' (長さ: %d)\n", remoteURL, len(remoteURL))
 	}
 
-	// HTTPS形式: https://github.com/user/repo.git
-	httpsRegex := regexp.MustCompile(`https://github\.com/([^/]+)/([^/]+?)(?:\.git)?/?$`)
-	if matches := httpsRegex.FindStringSubmatch(remoteURL); matches != nil {
-		owner := strings.TrimSpace(matches[1])
-		repo := strings.TrimSpace(matches[2])
-		if debug {
-			fmt.Printf("デバッグ: HTTPS形式でマッチ - Owner: '%s', Repo: '%s'\n", owner, repo)
-		}
-		return owner, repo
-	}
-
-	// SSH形式: git@github.com:user/repo.git
-	sshRegex := regexp.MustCompile(`git@github\.com:([^/]+)/([^/\s]+?)(?:\.git)?/?$`)
-	if matches := sshRegex.FindStringSubmatch(remoteURL); matches != nil {
-		owner := strings.TrimSpace(matches[1])
-		repo := strings.TrimSpace(matches[2])
+	remote, err := DefaultHost.ParseRemoteURL(remoteURL)
+	if err != nil {
 		if debug {
-			fmt.Printf("デバッグ: SSH形式でマッチ - Owner: '%s', Repo: '%s'\n", owner, repo)
+			fmt.Printf("デバッグ: %v\n", err)
 		}
-		return owner, repo
+		return "", ""
 	}
 
 	if debug {
-		fmt.Printf("デバッグ: どの形式にもマッチしませんでした\n")
+		fmt.Printf("デバッグ: %s形式でマッチ - Owner: '%s', Repo: '%s'\n", remote.Scheme, remote.Owner, remote.Repo)
 	}
-	return "", ""
+	return remote.Owner, remote.Repo
 }
 
 // FindGitDirs は指定されたディレクトリ以下のGitリポジトリを検索する
@@ -160,49 +143,98 @@ func RunCommandWithToken(dir, token string, command string, args ...string) (str
 }
 
 // RunGitPushWithToken はGitHubトークンを使用してgit pushを実行する
+// 認証にはoriginのURLを使用する
 func RunGitPushWithToken(dir, token string, pushArgs ...string) (string, string, error) {
+	return RunGitWithToken(dir, "origin", token, append([]string{"push"}, pushArgs...)...)
+}
+
+// RunGitWithToken は指定したリモートのURLを一時的にトークン付きURLに変更してGitコマンドを実行する
+func RunGitWithToken(dir, remote, token string, args ...string) (string, string, error) {
 	if token == "" {
-		// トークンが空の場合は通常のpushを実行
-		return RunCommand(dir, "git", append([]string{"push"}, pushArgs...)...)
+		// トークンが空の場合は通常のコマンドを実行
+		return RunCommand(dir, "git", args...)
 	}
 
 	// 現在のリモートURLを取得
-	originalURL, _, err := RunCommand(dir, "git", "remote", "get-url", "origin")
+	originalURL, _, err := RunCommand(dir, "git", "remote", "get-url", remote)
 	if err != nil {
 		return "", "", fmt.Errorf("リモートURL取得エラー: %v", err)
 	}
-	originalURL = strings.TrimSpace(originalURL)
 
 	// トークン付きHTTPS URLに変換
-	tokenURL, err := ConvertToTokenURL(originalURL, token)
+	tokenURL, err := ConvertToTokenURL(strings.TrimSpace(originalURL), token)
 	if err != nil {
 		return "", "", fmt.Errorf("トークンURL変換エラー: %v", err)
 	}
 
+	return RunGitWithURL(dir, remote, tokenURL, args...)
+}
+
+// RunGitWithURL は指定したリモートのURLを一時的に認証情報付きURLに変更してGitコマンドを実行する
+// コマンドの実行後はリモートURLを元に戻す
+func RunGitWithURL(dir, remote, authURL string, args ...string) (string, string, error) {
+	// 現在のリモートURLを取得
+	originalURL, _, err := RunCommand(dir, "git", "remote", "get-url", remote)
+	if err != nil {
+		return "", "", fmt.Errorf("リモートURL取得エラー: %v", err)
+	}
+	originalURL = strings.TrimSpace(originalURL)
+	if authURL == originalURL {
+		return RunCommand(dir, "git", args...)
+	}
+
 	// 一時的にリモートURLを変更
-	if _, _, err := RunCommand(dir, "git", "remote", "set-url", "origin", tokenURL); err != nil {
+	if _, _, err := RunCommand(dir, "git", "remote", "set-url", remote, authURL); err != nil {
 		return "", "", fmt.Errorf("リモートURL設定エラー: %v", err)
 	}
 
-	// プッシュを実行
-	stdout, stderr, pushErr := RunCommand(dir, "git", append([]string{"push"}, pushArgs...)...)
+	// コマンドを実行
+	stdout, stderr, runErr := RunCommand(dir, "git", args...)
 
 	// リモートURLを元に戻す
-	if _, _, err := RunCommand(dir, "git", "remote", "set-url", "origin", originalURL); err != nil {
+	if _, _, err := RunCommand(dir, "git", "remote", "set-url", remote, originalURL); err != nil {
 		fmt.Printf("⚠️  リモートURL復元エラー: %v\n", err)
 	}
 
-	return stdout, stderr, pushErr
+	return stdout, stderr, runErr
 }
 
 // ConvertToTokenURL はGitリモートURLをトークン付きHTTPS URLに変換する
+// ホストは元のURLのものを使用する（形式: https://token@github.com/owner/repo.git）
 func ConvertToTokenURL(remoteURL, token string) (string, error) {
-	owner, repo := ExtractRepoInfoFromURL(remoteURL)
-	if owner == "" || repo == "" {
+	remote, err := ParseRemoteURL(remoteURL)
+	if err != nil {
 		return "", fmt.Errorf("無効なリモートURL: %s", remoteURL)
 	}
+	host := Host{Name: remote.Host, Nested: true}
+	return host.TokenURL(remoteURL, token, "")
+}
 
-	// トークン付きHTTPS URLを生成
-	// 形式: https://token@github.com/owner/repo.git
-	return fmt.Sprintf("https://%s@github.com/%s/%s.git", token, owner, repo), nil
+// DirSize はディレクトリ以下のファイルサイズの合計を返す
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// FormatBytes はバイト数を人が読みやすい形式に変換する
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
 }
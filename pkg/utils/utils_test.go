@@ -273,3 +273,49 @@ func TestConvertToTokenURL(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatBytes(tt.size); result != tt.expected {
+			t.Errorf("FormatBytes(%d): 期待値 %s, 実際 %s", tt.size, tt.expected, result)
+		}
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/a.txt", make([]byte, 100), 0644)
+	os.MkdirAll(dir+"/sub", 0755)
+	os.WriteFile(dir+"/sub/b.txt", make([]byte, 50), 0644)
+
+	size, err := DirSize(dir)
+	if err != nil {
+		t.Fatalf("DirSizeでエラーが発生しました: %v", err)
+	}
+	if size != 150 {
+		t.Errorf("期待されるサイズ: 150, 実際: %d", size)
+	}
+}
+
+// TestConvertToTokenURLKeepsHost はトークン付きURLが元のURLのホストを使用することをテストする
+func TestConvertToTokenURLKeepsHost(t *testing.T) {
+	tokenURL, err := ConvertToTokenURL("git@ghe.corp:team/repo.git", "ghp_test123")
+	if err != nil || tokenURL != "https://ghp_test123@ghe.corp/team/repo.git" {
+		t.Errorf("トークン付きURLが期待値と異なります: %s, %v", tokenURL, err)
+	}
+	if owner, _ := ExtractRepoInfoFromURL("https://ghe.corp/team/repo.git"); owner != "" {
+		t.Errorf("github.com以外のURLが解析されました: %s", owner)
+	}
+}